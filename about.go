@@ -0,0 +1,182 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// StorageQuota describes the storage limit and usage of the authenticated user.
+// All values are in bytes. For users of an organization with pooled storage,
+// Limit and Usage apply to the whole organization.
+type StorageQuota struct {
+	Limit             int64 // Usage limit; 0 if storage is unlimited
+	Usage             int64 // Total usage across all Google services
+	UsageInDrive      int64 // Usage by files in Google Drive
+	UsageInDriveTrash int64 // Usage by trashed files in Google Drive
+}
+
+// Unlimited reports whether the user has no storage limit.
+func (q StorageQuota) Unlimited() bool {
+	return q.Limit <= 0
+}
+
+// Available returns the number of bytes left before the limit is reached,
+// or -1 if storage is unlimited. It is never less than -1.
+func (q StorageQuota) Available() int64 {
+	if q.Unlimited() {
+		return -1
+	}
+	return max(q.Limit-q.Usage, 0)
+}
+
+// About describes the authenticated user, their storage quota and the
+// capabilities of Google Drive.
+type About struct {
+	User          *User               // The authenticated user; nil if unknown
+	StorageQuota  StorageQuota        // Storage limit and usage
+	MaxUploadSize int64               // Maximum size of an uploaded file in bytes
+	ImportFormats map[string][]string // Workspace MIME types each source MIME type can be converted to
+	ExportFormats map[string][]string // Export MIME types of each Workspace MIME type
+}
+
+// GetAbout retrieves information about the authenticated user, their storage
+// quota, the maximum upload size and the supported import and export formats.
+// The quota is fetched on every call; the format maps are also stored in the
+// cache used by SupportedExportFormats and conversion uploads.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//
+// Returns:
+//   - *About: User, quota and capability information
+//   - error: Any error encountered during the API call
+//
+// Example:
+//
+//	about, err := client.GetAbout(ctx)
+//	q := about.StorageQuota
+//	fmt.Printf("%s uses %d of %d bytes (%d in trash)\n",
+//	    about.User.EmailAddress, q.Usage, q.Limit, q.UsageInDriveTrash)
+func (dc *DriveClient) GetAbout(ctx context.Context) (*About, error) {
+	a, err := dc.service.About.Get().
+		Context(ctx).
+		Fields("user, storageQuota, maxUploadSize, importFormats, exportFormats").
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get account information: %w", err)
+	}
+
+	about := &About{
+		User:          newUser(a.User),
+		StorageQuota:  newStorageQuota(a.StorageQuota),
+		MaxUploadSize: a.MaxUploadSize,
+		ImportFormats: a.ImportFormats,
+		ExportFormats: a.ExportFormats,
+	}
+
+	dc.formatsMu.Lock()
+	if dc.importFormats == nil {
+		dc.importFormats = a.ImportFormats
+		dc.exportFormats = a.ExportFormats
+		if dc.importFormats == nil {
+			dc.importFormats = map[string][]string{}
+		}
+	}
+	dc.formatsMu.Unlock()
+
+	return about, nil
+}
+
+// newStorageQuota converts a Drive storage quota to a StorageQuota.
+func newStorageQuota(q *drive.AboutStorageQuota) StorageQuota {
+	if q == nil {
+		return StorageQuota{}
+	}
+	return StorageQuota{
+		Limit:             q.Limit,
+		Usage:             q.Usage,
+		UsageInDrive:      q.UsageInDrive,
+		UsageInDriveTrash: q.UsageInDriveTrash,
+	}
+}
+
+// ErrQuotaExceeded is matched by QuotaError when an upload does not fit the
+// user's remaining storage or Drive's maximum upload size.
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// QuotaError is returned by uploads whose pre-flight check shows that the file
+// cannot be stored. errors.Is(err, ErrQuotaExceeded) reports true for it.
+type QuotaError struct {
+	FileSize      int64 // Size of the file to upload in bytes
+	Available     int64 // Bytes left in the storage quota; -1 if unlimited
+	MaxUploadSize int64 // Maximum upload size in bytes; 0 if unknown
+}
+
+func (e *QuotaError) Error() string {
+	if e.MaxUploadSize > 0 && e.FileSize > e.MaxUploadSize {
+		return fmt.Sprintf("file size %d exceeds the maximum upload size of %d bytes", e.FileSize, e.MaxUploadSize)
+	}
+	return fmt.Sprintf("file size %d exceeds the %d bytes of available storage", e.FileSize, e.Available)
+}
+
+// Is reports whether target is ErrQuotaExceeded.
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// SetQuotaCheck enables or disables the pre-flight storage check of uploads and
+// content updates. It is enabled by default and costs one About request per
+// upload, plus a lookup of the parent folder when one is given. Only a
+// *QuotaError blocks an upload; if the lookups fail, the upload proceeds
+// unchecked. Disable the check to save the requests when uploads are known to fit.
+// UploadOptions.SkipQuotaCheck and UpdateContentOptions.SkipQuotaCheck disable
+// the check for a single call. It must be called before the client is shared
+// between goroutines.
+//
+// Example:
+//
+//	client.SetQuotaCheck(false)
+//	fileID, err := client.UploadFile(ctx, "/logs/app.log", "", "")
+func (dc *DriveClient) SetQuotaCheck(enabled bool) {
+	dc.skipQuotaCheck = !enabled
+}
+
+// checkQuota returns a *QuotaError if an upload of size bytes exceeds the
+// maximum upload size, or if growth bytes do not fit the user's remaining
+// storage. growth equals size for new files and is the size difference for
+// content updates. The check is best-effort: if the quota cannot be retrieved,
+// for example because the client's scopes do not cover the about endpoint,
+// it returns nil and the upload proceeds.
+func (dc *DriveClient) checkQuota(ctx context.Context, size, growth int64) error {
+	a, err := dc.service.About.Get().
+		Context(ctx).
+		Fields("storageQuota, maxUploadSize").
+		Do()
+	if err != nil {
+		return nil
+	}
+
+	quota := newStorageQuota(a.StorageQuota)
+	available := quota.Available()
+	if (a.MaxUploadSize > 0 && size > a.MaxUploadSize) || (available >= 0 && growth > available) {
+		return &QuotaError{FileSize: size, Available: available, MaxUploadSize: a.MaxUploadSize}
+	}
+	return nil
+}
+
+// inSharedDrive reports whether a file or folder belongs to a shared drive,
+// whose storage does not count against the user's quota.
+func (dc *DriveClient) inSharedDrive(ctx context.Context, fileID string) (bool, error) {
+	f, err := dc.service.Files.Get(fileID).
+		Context(ctx).
+		SupportsAllDrives(true).
+		Fields("driveId").
+		Do()
+	if err != nil {
+		return false, fmt.Errorf("unable to get parent folder metadata: %w", err)
+	}
+	return f.DriveId != "", nil
+}
@@ -0,0 +1,458 @@
+package gdrive
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contentCacheSuffix is the file name suffix of cached bodies.
+const contentCacheSuffix = ".cache"
+
+// ContentCacheOptions configures EnableContentCache.
+type ContentCacheOptions struct {
+	// Dir is the directory holding cached bodies (required). It is created if
+	// needed, and bodies left by a previous run are reused.
+	Dir string
+
+	// MaxBytes bounds the total size of cached bodies. The least recently used
+	// bodies are evicted first. Defaults to 1 GiB.
+	MaxBytes int64
+}
+
+// ContentCacheStats reports the activity of a ContentCache.
+type ContentCacheStats struct {
+	Hits      int64 // Requests served from disk
+	Misses    int64 // Requests downloaded from Drive
+	Evictions int64 // Bodies removed to stay within MaxBytes
+	Entries   int   // Bodies currently cached
+	Bytes     int64 // Total size of the cached bodies
+}
+
+// ContentCache stores downloaded file bodies on disk. Binary files are keyed by
+// file ID and md5Checksum (or version when Drive reports no checksum), and
+// exports of Workspace documents by file ID, version and format, so a changed
+// file is never served stale. A cache hit costs one small metadata request; a
+// miss costs a second one after the download, and the body is only stored if
+// the file did not change while it was downloaded.
+//
+// While enabled, StreamFile and the Workspace export methods use the cache.
+// DownloadFile, PartialStreamFile, PartialDownloadFile, ParallelDownload and the
+// revision downloads bypass it. Safe for concurrent use by multiple goroutines.
+// A directory can only be used by one open cache at a time.
+type ContentCache struct {
+	dc       *DriveClient
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // key to element of order
+	order   *list.List               // front is most recently used
+	size    int64
+	stats   ContentCacheStats
+	closed  bool // no new bodies are stored once closed
+}
+
+// contentCacheDirs maps the absolute directory of every open cache to it,
+// so that two caches never manage the same directory.
+var (
+	contentCacheDirsMu sync.Mutex
+	contentCacheDirs   = make(map[string]*ContentCache)
+)
+
+// contentEntry is a cached body.
+type contentEntry struct {
+	name    string // file name in dir
+	size    int64
+	readers int  // open readers; eviction waits for them
+	removed bool // evicted while being read
+}
+
+// EnableContentCache stores downloaded file bodies in a local directory and
+// serves unchanged files from it. Enabling a cache replaces the client's previous
+// one, which is closed only once the new cache is ready; if enabling fails, the
+// previous cache stays in use. The new cache may reuse the previous cache's
+// directory. Only StreamFile and the Workspace export methods use the cache.
+//
+// Parameters:
+//   - opts: Cache directory and size bound
+//
+// Returns:
+//   - *ContentCache: The enabled cache
+//   - error: Any error encountered while preparing the directory, or if the
+//     directory is in use by an open cache of another client
+//
+// Example:
+//
+//	cache, err := client.EnableContentCache(gdrive.ContentCacheOptions{
+//	    Dir:      "/var/cache/reports",
+//	    MaxBytes: 5 << 30,
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer cache.Close()
+//
+//	// Downloads the template once; later calls only check its metadata
+//	_, err = client.StreamFile(ctx, templateID, w)
+func (dc *DriveClient) EnableContentCache(opts ContentCacheOptions) (*ContentCache, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("cache directory cannot be empty")
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = 1 << 30
+	}
+	dir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve cache directory: %w", err)
+	}
+	c := &ContentCache{
+		dc:       dc,
+		dir:      dir,
+		maxBytes: opts.MaxBytes,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+
+	// The previous cache keeps serving until the new one is ready, and may
+	// hand over its own directory.
+	previous := dc.contentCache.Load()
+	contentCacheDirsMu.Lock()
+	owner, inUse := contentCacheDirs[dir]
+	if inUse && owner != previous {
+		contentCacheDirsMu.Unlock()
+		return nil, fmt.Errorf("cache directory %s is already used by another content cache", dir)
+	}
+	contentCacheDirs[dir] = c
+	contentCacheDirsMu.Unlock()
+
+	if err := c.init(); err != nil {
+		contentCacheDirsMu.Lock()
+		if contentCacheDirs[dir] == c {
+			if inUse {
+				contentCacheDirs[dir] = owner
+			} else {
+				delete(contentCacheDirs, dir)
+			}
+		}
+		contentCacheDirsMu.Unlock()
+		return nil, err
+	}
+
+	dc.contentCache.Store(c)
+	if previous != nil {
+		previous.Close()
+	}
+	return c, nil
+}
+
+// init creates the cache directory and indexes its contents.
+func (c *ContentCache) init() error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("unable to create cache directory: %w", err)
+	}
+	return c.load()
+}
+
+// load indexes the bodies already in the directory, oldest access last,
+// and removes leftovers of interrupted downloads.
+func (c *ContentCache) load() error {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("unable to read cache directory: %w", err)
+	}
+
+	type found struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+	var bodies []found
+	for _, d := range dirEntries {
+		if d.IsDir() {
+			continue
+		}
+		name := d.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			os.Remove(filepath.Join(c.dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, contentCacheSuffix) {
+			continue
+		}
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+		bodies = append(bodies, found{name: name, size: info.Size(), modTime: info.ModTime()})
+	}
+
+	slices.SortFunc(bodies, func(a, b found) int { return b.modTime.Compare(a.modTime) })
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, b := range bodies {
+		key := strings.TrimSuffix(b.name, contentCacheSuffix)
+		c.entries[key] = c.order.PushBack(&contentEntry{name: b.name, size: b.size})
+		c.size += b.size
+	}
+	c.evictLocked()
+	return nil
+}
+
+// Close detaches the cache from its client and releases its directory for
+// another cache. Cached bodies stay on disk. Downloads still running finish
+// without storing their bodies.
+func (c *ContentCache) Close() {
+	c.dc.contentCache.CompareAndSwap(c, nil)
+
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+
+	contentCacheDirsMu.Lock()
+	if contentCacheDirs[c.dir] == c {
+		delete(contentCacheDirs, c.dir)
+	}
+	contentCacheDirsMu.Unlock()
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *ContentCache) Stats() ContentCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	stats.Bytes = c.size
+	return stats
+}
+
+// Purge removes every cached body.
+func (c *ContentCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.order.Len() > 0 {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+// fileKey returns the cache key of the content of a binary file.
+func fileKey(fileID, md5Checksum string, version int64) string {
+	if md5Checksum != "" {
+		return hashKey("file", fileID, md5Checksum)
+	}
+	return hashKey("file", fileID, "v"+strconv.FormatInt(version, 10))
+}
+
+// exportKey returns the cache key of an export of a Workspace document.
+func exportKey(fileID string, version int64, format ExportFormat) string {
+	return hashKey("export", fileID, "v"+strconv.FormatInt(version, 10), string(format))
+}
+
+// hashKey derives a file name safe key from its parts.
+func hashKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// serve copies a cached body to w. It reports false if key is not cached.
+func (c *ContentCache) serve(key string, w io.Writer) (int64, bool, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		c.mu.Unlock()
+		return 0, false, nil
+	}
+	entry := e.Value.(*contentEntry)
+	entry.readers++
+	c.order.MoveToFront(e)
+	c.mu.Unlock()
+	defer c.release(entry)
+
+	f, err := os.Open(filepath.Join(c.dir, entry.name))
+	if err != nil {
+		// The body vanished from disk; forget it and download again.
+		c.mu.Lock()
+		if current, ok := c.entries[key]; ok && current == e {
+			c.removeLocked(e)
+		}
+		c.stats.Misses++
+		c.mu.Unlock()
+		return 0, false, nil
+	}
+	defer f.Close()
+
+	c.mu.Lock()
+	c.stats.Hits++
+	c.mu.Unlock()
+
+	now := time.Now()
+	os.Chtimes(f.Name(), now, now) // Keeps the LRU order across restarts.
+
+	written, err := io.Copy(w, f)
+	return written, true, err
+}
+
+// release ends a read of entry, deleting its body if it was evicted meanwhile.
+func (c *ContentCache) release(entry *contentEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.readers--
+	if !entry.removed || entry.readers > 0 {
+		return
+	}
+	// A later fill may have stored a new body under the same name.
+	if _, live := c.entries[strings.TrimSuffix(entry.name, contentCacheSuffix)]; !live {
+		os.Remove(filepath.Join(c.dir, entry.name))
+	}
+}
+
+// fill runs fetch with a writer that copies to w and to a new cache body.
+// If fetch succeeds and currentKey still reports key afterwards, the body is
+// stored under key; otherwise the file changed during the download and the
+// body is dropped. If the body cannot be written, fetch still streams to w
+// and nothing is cached.
+func (c *ContentCache) fill(key string, w io.Writer, fetch func(w io.Writer) (int64, error), currentKey func() (string, error)) (int64, error) {
+	tmp, err := os.CreateTemp(c.dir, "fill-*.tmp")
+	if err != nil {
+		return fetch(w)
+	}
+	cw := &cacheWriter{w: w, body: tmp}
+	written, err := fetch(cw)
+	closeErr := tmp.Close()
+	if err != nil || cw.bodyErr != nil || closeErr != nil {
+		os.Remove(tmp.Name())
+		return written, err
+	}
+
+	if after, err := currentKey(); err != nil || after != key {
+		os.Remove(tmp.Name())
+		return written, nil
+	}
+	c.commit(key, tmp.Name(), written)
+	return written, nil
+}
+
+// commit moves a completed body into place and evicts old bodies if needed.
+func (c *ContentCache) commit(key, tmpPath string, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Bodies larger than the cache, filled concurrently or finished after
+	// Close are dropped.
+	if _, ok := c.entries[key]; ok || size > c.maxBytes || c.closed {
+		os.Remove(tmpPath)
+		return
+	}
+
+	name := key + contentCacheSuffix
+	if err := os.Rename(tmpPath, filepath.Join(c.dir, name)); err != nil {
+		os.Remove(tmpPath)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&contentEntry{name: name, size: size})
+	c.size += size
+	c.evictLocked()
+}
+
+// evictLocked removes least recently used bodies until the cache fits in maxBytes.
+func (c *ContentCache) evictLocked() {
+	for c.size > c.maxBytes && c.order.Len() > 0 {
+		c.removeLocked(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// removeLocked drops an entry and deletes its body unless it is being read.
+func (c *ContentCache) removeLocked(e *list.Element) {
+	entry := e.Value.(*contentEntry)
+	c.order.Remove(e)
+	delete(c.entries, strings.TrimSuffix(entry.name, contentCacheSuffix))
+	c.size -= entry.size
+	if entry.readers > 0 {
+		entry.removed = true
+		return
+	}
+	os.Remove(filepath.Join(c.dir, entry.name))
+}
+
+// cacheWriter writes to w and, until the first failure, to body.
+type cacheWriter struct {
+	w       io.Writer
+	body    *os.File
+	bodyErr error
+}
+
+func (cw *cacheWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	if cw.bodyErr == nil && n > 0 {
+		_, cw.bodyErr = cw.body.Write(p[:n])
+	}
+	return n, err
+}
+
+// streamFile streams a binary file through the content cache.
+func (c *ContentCache) streamFile(ctx context.Context, fileID string, w io.Writer, download func(w io.Writer) (int64, error)) (int64, error) {
+	currentKey := func() (string, error) {
+		meta, err := c.dc.service.Files.Get(fileID).
+			Context(ctx).
+			Fields("id, md5Checksum, version").
+			Do()
+		if err != nil {
+			return "", fmt.Errorf("unable to get file metadata: %w", err)
+		}
+		return fileKey(meta.Id, meta.Md5Checksum, meta.Version), nil
+	}
+
+	key, err := currentKey()
+	if err != nil {
+		return 0, err
+	}
+	written, ok, err := c.serve(key, w)
+	if ok {
+		if err != nil {
+			return written, fmt.Errorf("unable to stream file content: %w", err)
+		}
+		return written, nil
+	}
+	return c.fill(key, w, download, currentKey)
+}
+
+// exportFile exports a Workspace document through the content cache.
+func (c *ContentCache) exportFile(ctx context.Context, fileID string, format ExportFormat, w io.Writer, export func(w io.Writer) (int64, error)) (int64, error) {
+	currentKey := func() (string, error) {
+		meta, err := c.dc.service.Files.Get(fileID).
+			Context(ctx).
+			Fields("id, version").
+			Do()
+		if err != nil {
+			return "", fmt.Errorf("unable to get file metadata: %w", err)
+		}
+		return exportKey(meta.Id, meta.Version, format), nil
+	}
+
+	key, err := currentKey()
+	if err != nil {
+		return 0, err
+	}
+	written, ok, err := c.serve(key, w)
+	if ok {
+		if err != nil {
+			return written, fmt.Errorf("unable to write exported content: %w", err)
+		}
+		return written, nil
+	}
+	return c.fill(key, w, export, currentKey)
+}
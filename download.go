@@ -0,0 +1,150 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Google Drive MIME types with special handling.
+const (
+	folderMimeType   = "application/vnd.google-apps.folder"
+	shortcutMimeType = "application/vnd.google-apps.shortcut"
+)
+
+// maxShortcutHops bounds how many shortcuts are followed before giving up.
+const maxShortcutHops = 10
+
+// NotDownloadableError is returned by Download for files without downloadable
+// content: folders, and Workspace types that have no export format (e.g., Forms).
+// errors.Is(err, ErrNotExportable) reports true for the latter.
+type NotDownloadableError struct {
+	FileID   string // ID of the file that cannot be downloaded
+	Name     string // Display name of the file
+	MimeType string // MIME type of the file
+}
+
+func (e *NotDownloadableError) Error() string {
+	if e.MimeType == folderMimeType {
+		return fmt.Sprintf("%q (%s) is a folder and cannot be downloaded", e.Name, e.FileID)
+	}
+	return fmt.Sprintf("%q (%s) of type %s cannot be downloaded or exported", e.Name, e.FileID, e.MimeType)
+}
+
+// Is reports whether target is ErrNotExportable for Workspace documents.
+func (e *NotDownloadableError) Is(target error) bool {
+	return target == ErrNotExportable && e.MimeType != folderMimeType
+}
+
+// DownloadOptions configures Download.
+type DownloadOptions struct {
+	// ExportPolicy picks the export format per Workspace type. Types missing
+	// from the map, or mapped to a format Drive does not support for them,
+	// fall back to ExportPreferences.
+	ExportPolicy map[WorkspaceType]ExportFormat
+
+	// ExportPreferences lists export formats in order of preference for any
+	// Workspace type. If none is supported, the type's default format is used.
+	ExportPreferences []ExportFormat
+
+	// NoFollowShortcuts returns a NotDownloadableError for shortcuts
+	// instead of downloading their target.
+	NoFollowShortcuts bool
+}
+
+// DownloadResult describes what Download wrote.
+type DownloadResult struct {
+	FileID       string       // ID of the downloaded file (the target, if a shortcut was followed)
+	Name         string       // Display name of the downloaded file
+	MimeType     string       // MIME type of the file in Drive
+	ExportFormat ExportFormat // Format used for Workspace documents; empty for binary files
+	Extension    string       // Suggested file extension without the dot; may be empty for binary files
+	BytesWritten int64        // Number of bytes written
+}
+
+// Download writes the content of any Drive file to w, choosing the right method
+// from a single metadata request: binary files are streamed, Google Workspace
+// documents are exported according to opts, and shortcuts are followed to their
+// target.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of a file, Workspace document or shortcut
+//   - w: Destination writer for the content
+//   - opts: Export format policy and shortcut handling
+//
+// Returns:
+//   - *DownloadResult: Details of the downloaded file and chosen format
+//   - error: Any error encountered. A *NotDownloadableError is returned for folders,
+//     Workspace types that cannot be exported (e.g., Google Forms) and shortcuts
+//     without a target. Wraps ErrShortcutCycle if shortcuts loop or the chain is
+//     longer than 10 shortcuts, like ResolveShortcut
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//	result, err := client.Download(ctx, fileID, &buf, gdrive.DownloadOptions{
+//	    ExportPolicy: map[gdrive.WorkspaceType]gdrive.ExportFormat{
+//	        gdrive.WorkspaceSpreadsheet: gdrive.ExportFormatCSV,
+//	    },
+//	    ExportPreferences: []gdrive.ExportFormat{gdrive.ExportFormatPDF},
+//	})
+//	var notDownloadable *gdrive.NotDownloadableError
+//	if errors.As(err, &notDownloadable) {
+//	    log.Printf("skipping %s", notDownloadable.Name)
+//	}
+//	os.WriteFile(result.Name+"."+result.Extension, buf.Bytes(), 0644)
+func (dc *DriveClient) Download(ctx context.Context, fileID string, w io.Writer, opts DownloadOptions) (*DownloadResult, error) {
+	if fileID == "" {
+		return nil, errors.New("file ID cannot be empty")
+	}
+
+	file, err := dc.GetFile(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get file metadata: %w", err)
+	}
+	if file.MimeType == shortcutMimeType {
+		if opts.NoFollowShortcuts || file.Shortcut == nil || file.Shortcut.TargetID == "" {
+			return nil, &NotDownloadableError{FileID: file.ID, Name: file.Name, MimeType: file.MimeType}
+		}
+		if file, err = resolveShortcut(ctx, dc, file.Shortcut.TargetID); err != nil {
+			return nil, fmt.Errorf("unable to follow shortcut %s: %w", fileID, err)
+		}
+	}
+
+	result := &DownloadResult{
+		FileID:   file.ID,
+		Name:     file.Name,
+		MimeType: file.MimeType,
+	}
+
+	switch {
+	case file.MimeType == folderMimeType:
+		return nil, &NotDownloadableError{FileID: file.ID, Name: file.Name, MimeType: file.MimeType}
+
+	case strings.HasPrefix(file.MimeType, "application/vnd.google-apps."):
+		t := WorkspaceType(file.MimeType)
+		preferences := opts.ExportPreferences
+		if f, ok := opts.ExportPolicy[t]; ok {
+			preferences = append([]ExportFormat{f}, preferences...)
+		}
+
+		format, err := dc.negotiateExportFormat(ctx, t, preferences)
+		if err != nil {
+			return nil, &NotDownloadableError{FileID: file.ID, Name: file.Name, MimeType: file.MimeType}
+		}
+
+		result.ExportFormat = format
+		result.Extension = format.Extension()
+		result.BytesWritten, err = dc.ExportWorkspaceDocument(ctx, file.ID, w, format)
+		return result, err
+
+	default:
+		result.Extension = strings.TrimPrefix(filepath.Ext(file.Name), ".")
+		result.BytesWritten, err = dc.StreamFile(ctx, file.ID, w)
+		return result, err
+	}
+}
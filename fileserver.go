@@ -0,0 +1,330 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// FileServerOptions configures the handler returned by FileServer.
+type FileServerOptions struct {
+	// PathValue is the name of the route wildcard holding the file ID,
+	// e.g. "id" for a pattern like "GET /files/{id}". Defaults to "id".
+	// If the wildcard is missing or empty, the "id" query parameter is used instead.
+	PathValue string
+
+	// Inline serves files with "Content-Disposition: inline" so that browsers
+	// display them (PDF, video, images) instead of downloading them.
+	Inline bool
+
+	// CacheControl is sent as the Cache-Control header if not empty.
+	CacheControl string
+
+	// ErrorLog receives errors that occur after the response has started,
+	// such as an export failing midway. If nil, these errors are not logged.
+	ErrorLog *log.Logger
+}
+
+// FileServer returns an http.Handler that serves Google Drive files by ID.
+//
+// Binary files are served with Content-Type, Content-Length, Content-Disposition,
+// ETag (from md5Checksum, or version if no checksum exists) and Last-Modified headers.
+// Conditional requests (If-None-Match, If-Modified-Since, If-Range) and byte range
+// requests are supported; ranges are fetched with PartialStreamFile, so video
+// seeking and PDF previews only transfer the bytes they need, and full downloads
+// use StreamFile (and with it the content cache of a DriveClient).
+//
+// Shortcuts are resolved and their target is served; a shortcut whose target is
+// missing, or that is part of a cycle, is reported as 404 Not Found.
+//
+// Google Workspace documents are exported with ExportWorkspaceDocumentWithOptions,
+// so large documents fall back to exportLinks and the content cache is used when
+// enabled. The target format is taken from the "format" query parameter as a MIME
+// type or file extension (e.g., ?format=pdf). Exports do not support range requests.
+//
+// Only GET and HEAD are allowed. Drive "not found" and "forbidden" errors are
+// reported with the same status code; other errors are reported as 502 Bad Gateway.
+//
+// Parameters:
+//   - client: Client used to fetch metadata and content, usually a *DriveClient
+//   - opts: Route and header options
+//
+// Returns:
+//   - http.Handler: Handler serving files by ID
+//
+// Example:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("GET /files/{id}", gdrive.FileServer(client, gdrive.FileServerOptions{
+//	    Inline:       true,
+//	    CacheControl: "private, max-age=300",
+//	}))
+//	// GET /files/1aBc2DeF              -> binary file with Range support
+//	// GET /files/1xYz9WvU?format=pdf   -> Google Doc exported as PDF
+func FileServer(client FileServerClient, opts FileServerOptions) http.Handler {
+	if opts.PathValue == "" {
+		opts.PathValue = "id"
+	}
+	return &fileServer{client: client, opts: opts}
+}
+
+type fileServer struct {
+	client FileServerClient
+	opts   FileServerOptions
+}
+
+func (fs *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fileID := r.PathValue(fs.opts.PathValue)
+	if fileID == "" {
+		fileID = r.URL.Query().Get("id")
+	}
+	if fileID == "" {
+		http.Error(w, "File ID required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	file, err := resolveShortcut(ctx, fs.client, fileID)
+	if err != nil {
+		status := httpStatusFromError(err)
+		if errors.Is(err, ErrShortcutCycle) || errors.Is(err, ErrBrokenShortcut) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	fileID = file.ID
+
+	if file.MimeType == folderMimeType {
+		http.Error(w, "Folders cannot be served", http.StatusBadRequest)
+		return
+	}
+
+	modTime := file.ModifiedTime
+	if fs.opts.CacheControl != "" {
+		w.Header().Set("Cache-Control", fs.opts.CacheControl)
+	}
+
+	if strings.HasPrefix(file.MimeType, "application/vnd.google-apps.") {
+		fs.serveExport(w, r, fileID, file.Name, file.Version, modTime)
+		return
+	}
+
+	etag := `"v` + strconv.FormatInt(file.Version, 10) + `"`
+	if file.Md5Checksum != "" {
+		etag = `"` + file.Md5Checksum + `"`
+	}
+
+	w.Header().Set("Content-Type", file.MimeType)
+	w.Header().Set("Content-Disposition", contentDisposition(fs.opts.Inline, file.Name))
+	w.Header().Set("ETag", etag)
+
+	content := &rangeReadSeeker{
+		ctx:    ctx,
+		client: fs.client,
+		fileID: fileID,
+		size:   file.Size,
+	}
+	defer content.Close()
+
+	// ServeContent handles conditional requests, Range/If-Range, 206/416 and HEAD.
+	http.ServeContent(w, r, file.Name, modTime, content)
+}
+
+// serveExport streams a Workspace document exported to the requested format.
+func (fs *fileServer) serveExport(w http.ResponseWriter, r *http.Request, fileID, name string, version int64, modTime time.Time) {
+	formatParam := r.URL.Query().Get("format")
+	if formatParam == "" {
+		http.Error(w, "format parameter required for Google Workspace documents", http.StatusBadRequest)
+		return
+	}
+	format, ok := ParseExportFormat(formatParam)
+	if !ok {
+		http.Error(w, "Unknown export format: "+formatParam, http.StatusBadRequest)
+		return
+	}
+
+	etag := fmt.Sprintf(`"v%d-%s"`, version, format.Extension())
+	w.Header().Set("ETag", etag)
+	if !modTime.IsZero() {
+		w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, modTime) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", string(format))
+	w.Header().Set("Content-Disposition", contentDisposition(fs.opts.Inline, name+"."+format.Extension()))
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	tw := &trackingWriter{w: w}
+	_, err := fs.client.ExportWorkspaceDocumentWithOptions(r.Context(), fileID, tw, format, ExportOptions{})
+	if err == nil {
+		return
+	}
+	if !tw.started {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, http.StatusText(httpStatusFromError(err)), httpStatusFromError(err))
+		return
+	}
+	if fs.opts.ErrorLog != nil {
+		fs.opts.ErrorLog.Printf("gdrive: unable to stream export of %s: %v", fileID, err)
+	}
+}
+
+// trackingWriter records whether anything was written, which tells whether
+// an error can still be reported with a status code.
+type trackingWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func (tw *trackingWriter) Write(p []byte) (int, error) {
+	tw.started = true
+	return tw.w.Write(p)
+}
+
+// notModified evaluates If-None-Match and If-Modified-Since for a GET or HEAD request.
+// If-Modified-Since is ignored when If-None-Match is present, as required by RFC 9110.
+func notModified(r *http.Request, etag string, modTime time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for tag := range strings.SplitSeq(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || modTime.IsZero() {
+		return false
+	}
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	return !modTime.Truncate(time.Second).After(t)
+}
+
+// contentDisposition formats a Content-Disposition header value.
+// Non-ASCII file names are encoded using RFC 2231.
+func contentDisposition(inline bool, fileName string) string {
+	disposition := "attachment"
+	if inline {
+		disposition = "inline"
+	}
+	if v := mime.FormatMediaType(disposition, map[string]string{"filename": fileName}); v != "" {
+		return v
+	}
+	return disposition
+}
+
+// httpStatusFromError maps a Drive API error to a status code for HTTP handlers.
+// Not found and forbidden are passed through; everything else is a gateway error.
+func httpStatusFromError(err error) int {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusNotFound, http.StatusForbidden:
+			return apiErr.Code
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// rangeReadSeeker is an io.ReadSeeker over a Drive file.
+// Seeking is free; the next Read starts a download from the current offset to
+// the end of the file, which runs in its own goroutine and feeds a pipe.
+type rangeReadSeeker struct {
+	ctx    context.Context
+	client Downloader
+	fileID string
+	size   int64
+	offset int64
+	body   *io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (rs *rangeReadSeeker) Read(p []byte) (int, error) {
+	if rs.offset >= rs.size {
+		return 0, io.EOF
+	}
+
+	if rs.body == nil {
+		ctx, cancel := context.WithCancel(rs.ctx)
+		pr, pw := io.Pipe()
+		client, fileID, start, end := rs.client, rs.fileID, rs.offset, rs.size-1
+		go func() {
+			var err error
+			if start == 0 {
+				_, err = client.StreamFile(ctx, fileID, pw)
+			} else {
+				_, err = client.PartialStreamFile(ctx, fileID, pw, start, end)
+			}
+			pw.CloseWithError(err)
+		}()
+		rs.body, rs.cancel = pr, cancel
+	}
+
+	n, err := rs.body.Read(p)
+	rs.offset += int64(n)
+	return n, err
+}
+
+func (rs *rangeReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = rs.offset + offset
+	case io.SeekEnd:
+		abs = rs.size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if abs < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if abs != rs.offset {
+		rs.Close()
+		rs.offset = abs
+	}
+	return abs, nil
+}
+
+// Close stops the current download, if any.
+func (rs *rangeReadSeeker) Close() error {
+	if rs.body == nil {
+		return nil
+	}
+	rs.cancel()
+	err := rs.body.Close()
+	rs.body, rs.cancel = nil, nil
+	return err
+}
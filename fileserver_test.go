@@ -0,0 +1,92 @@
+package gdrive_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abiiranathan/gdrive"
+)
+
+// serve sends a request for path to handler, routed as "/files/{id}".
+func serve(handler http.Handler, method, path string, header map[string]string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.Handle("/files/{id}", handler)
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestFileServerRanges(t *testing.T) {
+	srv, client := newClient(t)
+	id := srv.AddFile("digits.txt", "", "text/plain", []byte("0123456789"))
+	handler := gdrive.FileServer(client, gdrive.FileServerOptions{})
+
+	tests := []struct {
+		rangeHeader  string
+		status       int
+		body         string
+		contentRange string
+	}{
+		{"", http.StatusOK, "0123456789", ""},
+		{"bytes=2-5", http.StatusPartialContent, "2345", "bytes 2-5/10"},
+		{"bytes=7-", http.StatusPartialContent, "789", "bytes 7-9/10"},
+		{"bytes=-3", http.StatusPartialContent, "789", "bytes 7-9/10"},
+		{"bytes=20-", http.StatusRequestedRangeNotSatisfiable, "", "bytes */10"},
+	}
+	for _, tt := range tests {
+		header := map[string]string{}
+		if tt.rangeHeader != "" {
+			header["Range"] = tt.rangeHeader
+		}
+		rec := serve(handler, http.MethodGet, "/files/"+id, header)
+		if rec.Code != tt.status {
+			t.Errorf("Range %q: status %d, want %d", tt.rangeHeader, rec.Code, tt.status)
+			continue
+		}
+		if got := rec.Header().Get("Content-Range"); got != tt.contentRange {
+			t.Errorf("Range %q: Content-Range %q, want %q", tt.rangeHeader, got, tt.contentRange)
+		}
+		if tt.status != http.StatusRequestedRangeNotSatisfiable && rec.Body.String() != tt.body {
+			t.Errorf("Range %q: body %q, want %q", tt.rangeHeader, rec.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestFileServerConditional(t *testing.T) {
+	srv, client := newClient(t)
+	id := srv.AddFile("digits.txt", "", "text/plain", []byte("0123456789"))
+	handler := gdrive.FileServer(client, gdrive.FileServerOptions{})
+
+	rec := serve(handler, http.MethodGet, "/files/"+id, nil)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status %d, ETag %q", rec.Code, etag)
+	}
+
+	srv.ResetRequests()
+	rec = serve(handler, http.MethodGet, "/files/"+id, map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: status %d, want 304", rec.Code)
+	}
+	if got := countRequests(srv, "GET", "/drive/v3/files/"+id); got != 1 {
+		t.Errorf("%d requests for a 304, want only the metadata", got)
+	}
+
+	// A stale If-Range validator gets the whole file.
+	rec = serve(handler, http.MethodGet, "/files/"+id, map[string]string{"Range": "bytes=0-1", "If-Range": `"stale"`})
+	if rec.Code != http.StatusOK || rec.Body.String() != "0123456789" {
+		t.Errorf("stale If-Range: status %d, body %q", rec.Code, rec.Body.String())
+	}
+
+	if rec := serve(handler, http.MethodGet, "/files/missing", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("missing file: status %d, want 404", rec.Code)
+	}
+	if rec := serve(handler, http.MethodPost, "/files/"+id, nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status %d, want 405", rec.Code)
+	}
+}
@@ -47,18 +47,29 @@
 package gdrive
 
 import (
+	"bufio"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
@@ -71,19 +82,64 @@ const MaxPageSize = 100
 // It provides high-level methods for common Drive operations.
 // Safe for concurrent use by multiple goroutines.
 type DriveClient struct {
-	service *drive.Service
+	service        *drive.Service
+	httpClient     *http.Client // authenticated client, used for exportLinks and other direct URLs
+	mimeDetector   MimeDetector
+	skipQuotaCheck bool // see SetQuotaCheck
+
+	formatsMu     sync.Mutex          // guards importFormats and exportFormats
+	importFormats map[string][]string // cached About.importFormats
+	exportFormats map[string][]string // cached About.exportFormats
+
+	metaCache    atomic.Pointer[MetadataCache] // optional, see EnableMetadataCache
+	contentCache atomic.Pointer[ContentCache]  // optional, see EnableContentCache
 }
 
 // FileInfo represents metadata about a Google Drive file.
 // This includes basic file information and the computed folder path.
 type FileInfo struct {
-	ID          string   // Unique file identifier in Google Drive
-	Name        string   // Display name of the file
-	MimeType    string   // MIME type (e.g., "application/pdf", "image/jpeg")
-	Size        int64    // Size in bytes (0 for Google Workspace documents)
-	WebViewLink string   // URL to view the file in a browser
-	Parents     []string // List of parent folder IDs
-	FolderPath  string   // Full folder path (e.g., "My Drive/Projects/2024")
+	ID             string            // Unique file identifier in Google Drive
+	Name           string            // Display name of the file
+	MimeType       string            // MIME type (e.g., "application/pdf", "image/jpeg")
+	Size           int64             // Size in bytes (0 for Google Workspace documents)
+	WebViewLink    string            // URL to view the file in a browser
+	Parents        []string          // List of parent folder IDs
+	FolderPath     string            // Full folder path (e.g., "My Drive/Projects/2024")
+	Md5Checksum    string            // MD5 of the content (binary files only)
+	HeadRevisionID string            // ID of the current revision (binary files only)
+	Version        int64             // Monotonically increasing version number
+	ModifiedTime   time.Time         // Last modification time
+	Properties     map[string]string // Public custom properties, visible to all apps
+	AppProperties  map[string]string // Private custom properties of the calling app
+	Shortcut       *ShortcutDetails  // Target of a shortcut; nil for other files
+	ThumbnailLink  string            // Short-lived link to a thumbnail (GetFile only); empty if Drive has none
+}
+
+// fileInfoFields is the partial response selector for the fields used by newFileInfo.
+const fileInfoFields = "id, name, mimeType, size, webViewLink, parents, md5Checksum, headRevisionId, version, modifiedTime, " +
+	"properties, appProperties, shortcutDetails"
+
+// newFileInfo converts Drive file metadata to a FileInfo.
+// FolderPath is left empty because resolving it requires the folder hierarchy.
+// Slices and maps are copied, since f may be shared with the metadata cache.
+func newFileInfo(f *drive.File) FileInfo {
+	modified, _ := time.Parse(time.RFC3339, f.ModifiedTime)
+	return FileInfo{
+		ID:             f.Id,
+		Name:           f.Name,
+		MimeType:       f.MimeType,
+		Size:           f.Size,
+		WebViewLink:    f.WebViewLink,
+		Parents:        slices.Clone(f.Parents),
+		Md5Checksum:    f.Md5Checksum,
+		HeadRevisionID: f.HeadRevisionId,
+		Version:        f.Version,
+		ModifiedTime:   modified,
+		Properties:     maps.Clone(f.Properties),
+		AppProperties:  maps.Clone(f.AppProperties),
+		Shortcut:       newShortcutDetails(f.ShortcutDetails),
+		ThumbnailLink:  f.ThumbnailLink,
+	}
 }
 
 // NewDriveClient is the internal helper to initialize the Google Drive service.
@@ -101,7 +157,7 @@ func NewDriveClient(ctx context.Context, client *http.Client) (*DriveClient, err
 	if err != nil {
 		return nil, fmt.Errorf("unable to create Drive service: %w", err)
 	}
-	return &DriveClient{service: srv}, nil
+	return &DriveClient{service: srv, httpClient: client}, nil
 }
 
 // NewDriveClientForServiceAccount creates a DriveClient using Service Account credentials.
@@ -214,18 +270,12 @@ func (dc *DriveClient) ListFiles(ctx context.Context) ([]FileInfo, error) {
 	folderMap := make(map[string]string)
 
 	// Fetch all folders
-	foldersCall := dc.service.Files.List().
-		Context(ctx).
-		Q("mimeType='application/vnd.google-apps.folder'").
-		Fields("files(id, name, parents)").
-		PageSize(1000)
-
-	foldersResp, err := foldersCall.Do()
+	folders, err := dc.listFolders(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve folders: %w", err)
 	}
 
-	for _, folder := range foldersResp.Files {
+	for _, folder := range folders {
 		folderMap[folder.Id] = folder.Name
 	}
 
@@ -245,7 +295,7 @@ func (dc *DriveClient) ListFiles(ctx context.Context) ([]FileInfo, error) {
 			if folderName, exists := folderMap[currentID]; exists {
 				pathParts = append([]string{folderName}, pathParts...)
 				// Find parent of current folder
-				for _, folder := range foldersResp.Files {
+				for _, folder := range folders {
 					if folder.Id == currentID && len(folder.Parents) > 0 {
 						currentID = folder.Parents[0]
 						break
@@ -267,7 +317,7 @@ func (dc *DriveClient) ListFiles(ctx context.Context) ([]FileInfo, error) {
 		call := dc.service.Files.List().
 			Context(ctx).
 			PageSize(MaxPageSize).
-			Fields("nextPageToken, files(id, name, mimeType, size, webViewLink, parents)")
+			Fields("nextPageToken, files(" + fileInfoFields + ")")
 
 		if pageToken != "" {
 			call = call.PageToken(pageToken)
@@ -284,15 +334,9 @@ func (dc *DriveClient) ListFiles(ctx context.Context) ([]FileInfo, error) {
 				continue
 			}
 
-			files = append(files, FileInfo{
-				ID:          item.Id,
-				Name:        item.Name,
-				MimeType:    item.MimeType,
-				Size:        item.Size,
-				WebViewLink: item.WebViewLink,
-				Parents:     item.Parents,
-				FolderPath:  buildPath(item.Parents),
-			})
+			info := newFileInfo(item)
+			info.FolderPath = buildPath(item.Parents)
+			files = append(files, info)
 		}
 
 		pageToken = r.NextPageToken
@@ -323,8 +367,44 @@ func (dc *DriveClient) ListFiles(ctx context.Context) ([]FileInfo, error) {
 //	// List files in root of My Drive
 //	files, err := client.ListFilesInFolder(ctx, "")
 func (dc *DriveClient) ListFilesInFolder(ctx context.Context, parentFolderID string) ([]FileInfo, error) {
+	return dc.ListFilesInFolderWithOptions(ctx, parentFolderID, ListOptions{})
+}
+
+// ListOptions configures ListFilesInFolderWithOptions.
+type ListOptions struct {
+	// IncludeShortcuts lists shortcuts, with their target in FileInfo.Shortcut.
+	// By default shortcuts are skipped like other zero-byte files.
+	IncludeShortcuts bool
+
+	// ResolveShortcuts replaces each listed shortcut with the metadata of its
+	// target (see ResolveShortcut), keeping the FolderPath of the shortcut.
+	// Shortcuts whose target cannot be resolved are listed unchanged.
+	// Implies IncludeShortcuts.
+	ResolveShortcuts bool
+}
+
+// ListFilesInFolderWithOptions is like ListFilesInFolder, with options
+// to include shortcuts and substitute their targets.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - parentFolderID: ID of the parent folder. Empty string lists root-level files in "My Drive"
+//   - opts: Shortcut handling options
+//
+// Returns:
+//   - []FileInfo: Slice of file metadata with folder paths
+//   - error: Any error encountered during API calls
+//
+// Example:
+//
+//	files, err := client.ListFilesInFolderWithOptions(ctx, folderID, gdrive.ListOptions{IncludeShortcuts: true})
+//	for _, f := range files {
+//	    if f.Shortcut != nil {
+//	        fmt.Printf("%s -> %s (%s)\n", f.Name, f.Shortcut.TargetID, f.Shortcut.TargetMimeType)
+//	    }
+//	}
+func (dc *DriveClient) ListFilesInFolderWithOptions(ctx context.Context, parentFolderID string, opts ListOptions) ([]FileInfo, error) {
 	files := make([]FileInfo, 0, MaxPageSize)
-	pageToken := ""
 
 	// Build query to filter by parent folder
 	query := "trashed=false"
@@ -336,18 +416,12 @@ func (dc *DriveClient) ListFilesInFolder(ctx context.Context, parentFolderID str
 	folderMap := make(map[string]string)
 	folderParentMap := make(map[string][]string)
 
-	foldersCall := dc.service.Files.List().
-		Context(ctx).
-		Q("mimeType='application/vnd.google-apps.folder'").
-		Fields("files(id, name, parents)").
-		PageSize(1000)
-
-	foldersResp, err := foldersCall.Do()
+	folders, err := dc.listFolders(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve folders: %w", err)
 	}
 
-	for _, folder := range foldersResp.Files {
+	for _, folder := range folders {
 		folderMap[folder.Id] = folder.Name
 		folderParentMap[folder.Id] = folder.Parents
 	}
@@ -382,51 +456,63 @@ func (dc *DriveClient) ListFilesInFolder(ctx context.Context, parentFolderID str
 		return "My Drive/" + strings.Join(pathParts, "/")
 	}
 
-	// Fetch files
-	for {
-		call := dc.service.Files.List().
-			Context(ctx).
-			Q(query).
-			PageSize(MaxPageSize).
-			Fields("nextPageToken, files(id, name, mimeType, size, webViewLink, parents)")
+	items, err := dc.listChildren(ctx, parentFolderID, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve files: %w", err)
+	}
 
-		if pageToken != "" {
-			call = call.PageToken(pageToken)
+	for _, item := range items {
+		isShortcut := item.MimeType == shortcutMimeType && (opts.IncludeShortcuts || opts.ResolveShortcuts)
+		if item.MimeType == "application/vnd.google-apps.folder" || (item.Size == 0 && !isShortcut) {
+			continue
 		}
 
-		r, err := call.Do()
-		if err != nil {
-			return nil, fmt.Errorf("unable to retrieve files: %w", err)
+		info := newFileInfo(item)
+		if isShortcut && opts.ResolveShortcuts {
+			if target, err := dc.ResolveShortcut(ctx, item.Id); err == nil {
+				info = *target
+			}
 		}
+		info.FolderPath = buildPath(item.Parents)
+		files = append(files, info)
+	}
 
-		for _, item := range r.Files {
-			if item.MimeType == "application/vnd.google-apps.folder" || item.Size == 0 {
-				continue
-			}
+	return files, nil
+}
 
-			files = append(files, FileInfo{
-				ID:          item.Id,
-				Name:        item.Name,
-				MimeType:    item.MimeType,
-				Size:        item.Size,
-				WebViewLink: item.WebViewLink,
-				Parents:     item.Parents,
-				FolderPath:  buildPath(item.Parents),
-			})
-		}
+// GetFile retrieves the metadata of a single file or folder, including the
+// ThumbnailLink that listings leave empty.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: Unique Google Drive file identifier
+//
+// Returns:
+//   - *FileInfo: File metadata (FolderPath is not populated)
+//   - error: Any error encountered during the API call
+//
+// Example:
+//
+//	info, err := client.GetFile(ctx, "1aBc2DeF")
+//	fmt.Printf("%s (%d bytes, revision %s)\n", info.Name, info.Size, info.HeadRevisionID)
+func (dc *DriveClient) GetFile(ctx context.Context, fileID string) (*FileInfo, error) {
+	if fileID == "" {
+		return nil, errors.New("file ID cannot be empty")
+	}
 
-		pageToken = r.NextPageToken
-		if pageToken == "" {
-			break
-		}
+	file, err := dc.getFileMetadata(ctx, fileID, fileInfoFields+", thumbnailLink")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get file metadata: %w", err)
 	}
 
-	return files, nil
+	info := newFileInfo(file)
+	return &info, nil
 }
 
 // StreamFile downloads a file from Google Drive and streams its content to the provided io.Writer.
 // This is highly efficient for large files and web responses (e.g., http.ResponseWriter).
 // The entire file content is copied to the writer without loading it into memory.
+// If a content cache is enabled (see EnableContentCache), unchanged files are served from disk.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
@@ -450,6 +536,16 @@ func (dc *DriveClient) StreamFile(ctx context.Context, fileID string, w io.Write
 		return 0, errors.New("file ID cannot be empty")
 	}
 
+	if c := dc.contentCache.Load(); c != nil {
+		return c.streamFile(ctx, fileID, w, func(w io.Writer) (int64, error) {
+			return dc.streamFile(ctx, fileID, w)
+		})
+	}
+	return dc.streamFile(ctx, fileID, w)
+}
+
+// streamFile downloads the content of a file to w without the content cache.
+func (dc *DriveClient) streamFile(ctx context.Context, fileID string, w io.Writer) (int64, error) {
 	resp, err := dc.service.Files.Get(fileID).Context(ctx).Download()
 	if err != nil {
 		return 0, fmt.Errorf("unable to download file: %w", err)
@@ -468,9 +564,27 @@ func (dc *DriveClient) StreamFile(ctx context.Context, fileID string, w io.Write
 	return written, nil
 }
 
+// ErrChecksumMismatch is returned by DownloadFile when the downloaded content
+// does not match the checksum reported by Google Drive.
+// The partial file is removed so the next attempt starts from scratch.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// partialSuffix is appended to the output path to name the temporary file
+// that DownloadFile writes to before renaming it into place.
+const partialSuffix = ".partial"
+
 // DownloadFile downloads a file from Google Drive to a local file path.
 // The parent directory is created automatically if it doesn't exist.
-// This is a convenience wrapper around StreamFile for file-based downloads.
+// The content cache (see EnableContentCache) is not used; the file is always downloaded.
+//
+// The content is first written to a temporary sibling file (outputPath + ".partial").
+// If a previous attempt was interrupted, the download resumes from the size of that
+// file using an HTTP range request. Once the transfer completes, the content is
+// verified against the md5Checksum or sha256Checksum reported by Drive, synced to
+// disk and atomically renamed to outputPath. A file at outputPath is therefore
+// either absent or complete.
+//
+// Note: Google Workspace documents cannot be downloaded. Use ExportWorkspaceDocumentToFile instead.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
@@ -478,42 +592,181 @@ func (dc *DriveClient) StreamFile(ctx context.Context, fileID string, w io.Write
 //   - outputPath: Local file system path where file will be saved
 //
 // Returns:
-//   - int64: Number of bytes written
-//   - error: Any error encountered during download or file creation
+//   - int64: Size of the downloaded file in bytes, including any resumed portion
+//   - error: Any error encountered during download or file creation.
+//     Wraps ErrChecksumMismatch if the content fails verification.
 //
 // Example:
 //
 //	bytesWritten, err := client.DownloadFile(ctx, "1aBc2DeF", "/downloads/document.pdf")
+//	if errors.Is(err, gdrive.ErrChecksumMismatch) {
+//	    // Corrupt transfer; the partial file was discarded, retry from scratch
+//	}
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
 //	fmt.Printf("Downloaded %d bytes\n", bytesWritten)
 func (dc *DriveClient) DownloadFile(ctx context.Context, fileID, outputPath string) (int64, error) {
+	if fileID == "" {
+		return 0, errors.New("file ID cannot be empty")
+	}
 	if outputPath == "" {
 		return 0, errors.New("output path cannot be empty")
 	}
 
+	meta, err := dc.service.Files.Get(fileID).
+		Context(ctx).
+		Fields("id, size, md5Checksum, sha256Checksum").
+		Do()
+	if err != nil {
+		return 0, fmt.Errorf("unable to get file metadata: %w", err)
+	}
+
 	dir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return 0, fmt.Errorf("unable to create output directory: %w", err)
 	}
 
-	out, err := os.Create(outputPath)
+	partialPath := outputPath + partialSuffix
+	out, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0644)
 	if err != nil {
 		return 0, fmt.Errorf("unable to create output file: %w", err)
 	}
 	defer out.Close()
 
-	written, err := dc.StreamFile(ctx, fileID, out)
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, fmt.Errorf("unable to seek output file: %w", err)
+	}
+
+	// A partial file larger than the remote file belongs to a different version.
+	if offset > meta.Size {
+		if err := out.Truncate(0); err != nil {
+			return 0, fmt.Errorf("unable to truncate output file: %w", err)
+		}
+		offset = 0
+	}
+
+	if offset < meta.Size || meta.Size == 0 {
+		if _, err := dc.resumeDownload(ctx, fileID, out, offset); err != nil {
+			return 0, fmt.Errorf("unable to download file: %w", err)
+		}
+	}
+
+	if err := verifyChecksum(out, meta.Md5Checksum, meta.Sha256Checksum); err != nil {
+		out.Close()
+		os.Remove(partialPath)
+		return 0, err
+	}
+
+	size, err := out.Seek(0, io.SeekEnd)
 	if err != nil {
-		return written, fmt.Errorf("unable to download file: %w", err)
+		return 0, fmt.Errorf("unable to seek output file: %w", err)
 	}
+	if err := out.Sync(); err != nil {
+		return 0, fmt.Errorf("unable to sync output file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return 0, fmt.Errorf("unable to close output file: %w", err)
+	}
+	if err := os.Rename(partialPath, outputPath); err != nil {
+		return 0, fmt.Errorf("unable to move output file into place: %w", err)
+	}
+	syncDir(dir)
+
+	return size, nil
+}
+
+// resumeDownload appends the content of a file starting at offset to out.
+// If the server ignores the range request and sends the whole file,
+// out is truncated and rewritten from the beginning.
+func (dc *DriveClient) resumeDownload(ctx context.Context, fileID string, out *os.File, offset int64) (int64, error) {
+	call := dc.service.Files.Get(fileID).Context(ctx)
+	if offset > 0 {
+		call.Header().Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := call.Download()
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
 
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// Append to the existing partial content
+	case http.StatusOK:
+		if err := out.Truncate(0); err != nil {
+			return 0, fmt.Errorf("unable to truncate output file: %w", err)
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("unable to seek output file: %w", err)
+		}
+	default:
+		return 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	written, err := io.Copy(out, resp.Body)
+	if err != nil {
+		return written, fmt.Errorf("unable to stream file content: %w", err)
+	}
 	return written, nil
 }
 
+// verifyChecksum hashes the full content of f and compares it against the
+// checksums reported by Drive. sha256 is preferred when available.
+// Files without a checksum (e.g. some shared drive items) are not verified.
+func verifyChecksum(f *os.File, md5Sum, sha256Sum string) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to seek output file: %w", err)
+	}
+	return compareChecksum(f, md5Sum, sha256Sum)
+}
+
+// compareChecksum hashes everything read from r and compares it against the
+// checksums reported by Drive, like verifyChecksum.
+func compareChecksum(r io.Reader, md5Sum, sha256Sum string) error {
+	var h hash.Hash
+	var expected, algorithm string
+	switch {
+	case sha256Sum != "":
+		h, expected, algorithm = sha256.New(), sha256Sum, "sha256"
+	case md5Sum != "":
+		h, expected, algorithm = md5.New(), md5Sum, "md5"
+	default:
+		return nil
+	}
+
+	if _, err := io.Copy(h, r); err != nil {
+		return fmt.Errorf("unable to hash output file: %w", err)
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("%w: %s expected %s, got %s", ErrChecksumMismatch, algorithm, expected, actual)
+	}
+	return nil
+}
+
+// syncDir flushes a directory entry to disk so that a rename survives a crash.
+// Errors are ignored because not all platforms support syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
 // UploadFile uploads a local file to Google Drive.
-// The MIME type is automatically detected from the file content.
+// The MIME type is automatically detected from the file content and extension
+// using the client's MimeDetector (see SetMimeDetector).
+// Before any content is sent, the file size is checked against the user's
+// remaining storage and Drive's maximum upload size, unless the target folder is
+// in a shared drive. The check is best-effort: if the quota or the parent folder
+// cannot be read, the file is uploaded unchecked. Use SetQuotaCheck(false) to
+// skip the check and its one or two extra requests.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
@@ -523,7 +776,8 @@ func (dc *DriveClient) DownloadFile(ctx context.Context, fileID, outputPath stri
 //
 // Returns:
 //   - string: File ID of the uploaded file in Google Drive
-//   - error: Any error encountered during upload
+//   - error: Any error encountered during upload. A *QuotaError (matching
+//     ErrQuotaExceeded) if the file does not fit the remaining storage
 //
 // Example:
 //
@@ -540,50 +794,16 @@ func (dc *DriveClient) UploadFile(ctx context.Context, filePath, fileName, paren
 		fileName = filepath.Base(filePath)
 	}
 
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", fmt.Errorf("unable to open file: %w", err)
-	}
-	defer file.Close()
-
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return "", fmt.Errorf("unable to stat file: %w", err)
-	}
-
-	// Detect MIME type
-	buffer := make([]byte, 512)
-	n, err := file.Read(buffer)
-	if err != nil && err != io.EOF {
-		return "", fmt.Errorf("unable to read file for MIME detection: %w", err)
-	}
-	mimeType := http.DetectContentType(buffer[:n])
-
-	// Reset file pointer
-	if _, err := file.Seek(0, 0); err != nil {
-		return "", fmt.Errorf("unable to reset file pointer: %w", err)
-	}
-
-	fileMeta := &drive.File{
-		Name:     fileName,
-		MimeType: mimeType,
-	}
-
-	if parentFolderID != "" {
-		fileMeta.Parents = []string{parentFolderID}
-	}
-
-	uploadedFile, err := dc.service.Files.Create(fileMeta).
-		Context(ctx).
-		Media(file).
-		Fields("id, name, mimeType, size, parents, webViewLink").
-		Do()
+	uploadedFile, err := dc.uploadFile(ctx, filePath, UploadOptions{
+		FileName:       fileName,
+		ParentFolderID: parentFolderID,
+	})
 	if err != nil {
-		return "", fmt.Errorf("unable to upload file: %w", err)
+		return "", err
 	}
 
 	fmt.Printf("File uploaded successfully: %s (ID: %s, Size: %d bytes)\n",
-		uploadedFile.Name, uploadedFile.Id, fileInfo.Size())
+		uploadedFile.Name, uploadedFile.Id, uploadedFile.Size)
 
 	return uploadedFile.Id, nil
 }
@@ -596,7 +816,7 @@ func (dc *DriveClient) UploadFile(ctx context.Context, filePath, fileName, paren
 //   - ctx: Context for cancellation and timeout
 //   - reader: Source reader containing file content
 //   - fileName: Display name in Google Drive (required)
-//   - mimeType: MIME type of the file. Use "application/octet-stream" if unknown
+//   - mimeType: MIME type of the file. If empty, it is detected from fileName and the leading content
 //   - parentFolderID: ID of the parent folder. Empty string uploads to "My Drive" root
 //
 // Returns:
@@ -611,16 +831,62 @@ func (dc *DriveClient) UploadFile(ctx context.Context, filePath, fileName, paren
 //	fileID, err := client.UploadFileFromReader(ctx, file, header.Filename,
 //	    header.Header.Get("Content-Type"), "")
 func (dc *DriveClient) UploadFileFromReader(ctx context.Context, reader io.Reader, fileName, mimeType, parentFolderID string) (string, error) {
+	info, err := dc.UploadFileFromReaderWithInfo(ctx, reader, fileName, mimeType, parentFolderID)
+	if err != nil {
+		return "", err
+	}
+	return info.ID, nil
+}
+
+// UploadFileFromReaderWithInfo is like UploadFileFromReader, but returns the
+// metadata of the created file from the upload response, without a second request.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - reader: Source reader containing file content
+//   - fileName: Display name in Google Drive (required)
+//   - mimeType: MIME type of the file. If empty, it is detected from fileName and the leading content
+//   - parentFolderID: ID of the parent folder. Empty string uploads to "My Drive" root
+//
+// Returns:
+//   - *FileInfo: Metadata of the created file (FolderPath is not populated)
+//   - error: Any error encountered during upload
+//
+// Example:
+//
+//	info, err := client.UploadFileFromReaderWithInfo(ctx, r.Body, "notes.txt", "text/plain", folderID)
+//	fmt.Printf("%s (%d bytes, md5 %s)\n", info.ID, info.Size, info.Md5Checksum)
+func (dc *DriveClient) UploadFileFromReaderWithInfo(ctx context.Context, reader io.Reader, fileName, mimeType, parentFolderID string) (*FileInfo, error) {
 	if reader == nil {
-		return "", errors.New("reader cannot be nil")
+		return nil, errors.New("reader cannot be nil")
 	}
 	if fileName == "" {
-		return "", errors.New("file name cannot be empty")
+		return nil, errors.New("file name cannot be empty")
 	}
 	if mimeType == "" {
-		mimeType = "application/octet-stream"
+		br := bufio.NewReaderSize(reader, SniffLen)
+		head, err := br.Peek(SniffLen)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, fmt.Errorf("unable to read content for MIME detection: %w", err)
+		}
+		mimeType = dc.detectMimeType(fileName, head)
+		reader = br
 	}
 
+	uploadedFile, err := dc.createFromReader(ctx, reader, fileName, mimeType, parentFolderID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to upload file: %w", err)
+	}
+
+	fmt.Printf("File uploaded successfully: %s (ID: %s)\n", uploadedFile.Name, uploadedFile.Id)
+
+	info := newFileInfo(uploadedFile)
+	return &info, nil
+}
+
+// createFromReader creates a file with the content of reader and returns
+// its metadata with the fields listed in fileInfoFields.
+func (dc *DriveClient) createFromReader(ctx context.Context, reader io.Reader, fileName, mimeType, parentFolderID string) (*drive.File, error) {
 	fileMeta := &drive.File{
 		Name:     fileName,
 		MimeType: mimeType,
@@ -630,17 +896,16 @@ func (dc *DriveClient) UploadFileFromReader(ctx context.Context, reader io.Reade
 		fileMeta.Parents = []string{parentFolderID}
 	}
 
-	uploadedFile, err := dc.service.Files.Create(fileMeta).
+	created, err := dc.service.Files.Create(fileMeta).
 		Context(ctx).
 		Media(reader).
-		Fields("id, name, mimeType, size, parents, webViewLink").
+		Fields(fileInfoFields).
 		Do()
 	if err != nil {
-		return "", fmt.Errorf("unable to upload file: %w", err)
+		return nil, err
 	}
-
-	fmt.Printf("File uploaded successfully: %s (ID: %s)\n", uploadedFile.Name, uploadedFile.Id)
-	return uploadedFile.Id, nil
+	dc.invalidateMetadata(created.Id, created.Parents, false)
+	return created, nil
 }
 
 // CreateFolder creates a new folder in Google Drive.
@@ -677,11 +942,12 @@ func (dc *DriveClient) CreateFolder(ctx context.Context, folderName, parentFolde
 
 	folder, err := dc.service.Files.Create(folderMeta).
 		Context(ctx).
-		Fields("id, name").
+		Fields("id, name, parents").
 		Do()
 	if err != nil {
 		return "", fmt.Errorf("unable to create folder: %w", err)
 	}
+	dc.invalidateMetadata(folder.Id, folder.Parents, true)
 
 	fmt.Printf("Folder created successfully: %s (ID: %s)\n", folder.Name, folder.Id)
 	return folder.Id, nil
@@ -716,6 +982,7 @@ func (dc *DriveClient) TrashFile(ctx context.Context, fileID string) error {
 		return fmt.Errorf("unable to trash file: %w", err)
 	}
 
+	dc.invalidateMetadata(fileID, nil, false)
 	fmt.Printf("File moved to trash: %s\n", fileID)
 	return nil
 }
@@ -737,20 +1004,24 @@ func (dc *DriveClient) RestoreFile(ctx context.Context, fileID string) error {
 		return errors.New("file ID cannot be empty")
 	}
 
+	// Trashed is false, its zero value, so it must be sent explicitly.
 	_, err := dc.service.Files.Update(fileID, &drive.File{
-		Trashed: false,
+		Trashed:         false,
+		ForceSendFields: []string{"Trashed"},
 	}).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("unable to restore file: %w", err)
 	}
 
+	dc.invalidateMetadata(fileID, nil, false)
 	fmt.Printf("File restored from trash: %s\n", fileID)
 	return nil
 }
 
 // DeleteFile permanently deletes a file or folder from Google Drive.
 // WARNING: This action is irreversible. The file cannot be recovered.
-// Consider using TrashFile instead for recoverable deletion.
+// Consider using TrashFile instead for recoverable deletion, or DeleteTree
+// for folders, which plans the deletion and requires confirmation.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
@@ -775,6 +1046,7 @@ func (dc *DriveClient) DeleteFile(ctx context.Context, fileID string) error {
 		return fmt.Errorf("unable to delete file permanently: %w", err)
 	}
 
+	dc.invalidateMetadata(fileID, nil, false)
 	fmt.Printf("File permanently deleted: %s\n", fileID)
 	return nil
 }
@@ -790,6 +1062,7 @@ type PartialDownloadOptions struct {
 // PartialDownloadFile downloads a specific byte range of a file from Google Drive.
 // This is useful for resumable downloads, streaming large files in chunks,
 // or implementing HTTP range requests.
+// The content cache (see EnableContentCache) is not used.
 //
 // Note: Partial downloads are not supported for Google Workspace documents
 // (Google Docs, Sheets, Slides, etc.). Use ExportWorkspaceDocument instead.
@@ -824,13 +1097,13 @@ func (dc *DriveClient) PartialDownloadFile(ctx context.Context, fileID string, w
 		return 0, errors.New("start byte must be less than or equal to end byte")
 	}
 
-	call := dc.service.Revisions.Get(fileID, fileID).Context(ctx)
+	call := dc.service.Files.Get(fileID).Context(ctx)
 	rangeHeader := fmt.Sprintf("bytes=%d-%d", opts.StartByte, opts.EndByte)
 	call.Header().Set("Range", rangeHeader)
 
 	resp, err := call.Download()
 	if err != nil {
-		return 0, fmt.Errorf("unable to download revision: %w", err)
+		return 0, fmt.Errorf("unable to download file: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -848,6 +1121,7 @@ func (dc *DriveClient) PartialDownloadFile(ctx context.Context, fileID string, w
 
 // PartialStreamFile is a convenience wrapper around PartialDownloadFile.
 // Downloads a specific byte range of a file to the provided writer.
+// Like PartialDownloadFile, it always downloads from Drive and bypasses the content cache.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
@@ -893,8 +1167,66 @@ const (
 	ExportFormatSVG  ExportFormat = "image/svg+xml"                                                             // SVG (Drawings)
 	ExportFormatCSV  ExportFormat = "text/csv"                                                                  // CSV (Sheets)
 	ExportFormatEPUB ExportFormat = "application/epub+zip"                                                      // EPUB (Docs)
+	ExportFormatMD   ExportFormat = "text/markdown"                                                             // Markdown (Docs)
+	ExportFormatTSV  ExportFormat = "text/tab-separated-values"                                                 // TSV (Sheets)
+	ExportFormatJSON ExportFormat = "application/vnd.google-apps.script+json"                                   // Apps Script project (Scripts)
 )
 
+// exportFormatExtensions maps export formats to their conventional file extensions.
+var exportFormatExtensions = map[ExportFormat]string{
+	ExportFormatPDF:  "pdf",
+	ExportFormatDOCX: "docx",
+	ExportFormatXLSX: "xlsx",
+	ExportFormatPPTX: "pptx",
+	ExportFormatODT:  "odt",
+	ExportFormatODS:  "ods",
+	ExportFormatODP:  "odp",
+	ExportFormatRTF:  "rtf",
+	ExportFormatTXT:  "txt",
+	ExportFormatHTML: "html",
+	ExportFormatZIP:  "zip",
+	ExportFormatJPEG: "jpg",
+	ExportFormatPNG:  "png",
+	ExportFormatSVG:  "svg",
+	ExportFormatCSV:  "csv",
+	ExportFormatEPUB: "epub",
+	ExportFormatMD:   "md",
+	ExportFormatTSV:  "tsv",
+	ExportFormatJSON: "json",
+}
+
+// Extension returns the conventional file extension for the export format,
+// without a leading dot (e.g., "pdf", "xlsx"). Unknown formats return "bin".
+func (f ExportFormat) Extension() string {
+	if ext, ok := exportFormatExtensions[f]; ok {
+		return ext
+	}
+	return "bin"
+}
+
+// ParseExportFormat converts a MIME type (e.g., "application/pdf") or a file
+// extension with or without a leading dot (e.g., "pdf", ".xlsx") into an ExportFormat.
+// Returns false if the extension is not known.
+func ParseExportFormat(s string) (ExportFormat, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "", false
+	}
+	if strings.Contains(s, "/") {
+		return ExportFormat(s), true
+	}
+	s = strings.TrimPrefix(s, ".")
+	if s == "jpeg" {
+		s = "jpg"
+	}
+	for format, ext := range exportFormatExtensions {
+		if ext == s {
+			return format, true
+		}
+	}
+	return "", false
+}
+
 // ExportWorkspaceDocument exports a Google Workspace document to the specified format.
 // Supported formats depend on the document type:
 //   - Google Docs: PDF, DOCX, ODT, RTF, TXT, HTML, EPUB, ZIP
@@ -902,7 +1234,9 @@ const (
 //   - Google Slides: PDF, PPTX, ODP, TXT, JPEG, PNG, SVG
 //   - Google Drawings: PDF, JPEG, PNG, SVG
 //
-// Note: Exported content is limited to 10 MB by Google Drive API.
+// Note: Files.Export is limited to 10 MB by Google Drive API. When a document
+// exceeds that limit, the export is retried automatically through the file's
+// exportLinks URL, which has no such limit.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
@@ -923,6 +1257,41 @@ const (
 //	// Export Google Sheet to Excel
 //	bytesWritten, err := client.ExportWorkspaceDocument(ctx, sheetID, &buf, gdrive.ExportFormatXLSX)
 func (dc *DriveClient) ExportWorkspaceDocument(ctx context.Context, fileID string, w io.Writer, format ExportFormat) (int64, error) {
+	return dc.ExportWorkspaceDocumentWithOptions(ctx, fileID, w, format, ExportOptions{})
+}
+
+// ExportOptions configures ExportWorkspaceDocumentWithOptions.
+type ExportOptions struct {
+	// UseExportLinks skips Files.Export and downloads directly from the file's
+	// exportLinks URL. Use it for documents known to exceed the 10 MB export limit
+	// to save the failed first attempt.
+	UseExportLinks bool
+}
+
+// ExportWorkspaceDocumentWithOptions exports a Google Workspace document like
+// ExportWorkspaceDocument, with control over how the export is performed.
+//
+// Without options, Files.Export is used and the exportLinks URL is only tried if
+// Drive rejects the export for exceeding its size limit. The exportLinks download
+// goes through the client's authenticated HTTP client and is streamed to w.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the Google Workspace document
+//   - w: Destination writer for the exported content
+//   - format: Desired export format (use ExportFormat constants)
+//   - opts: Export options
+//
+// Returns:
+//   - int64: Number of bytes written
+//   - error: Any error encountered during export
+//
+// Example:
+//
+//	// Export a large slide deck with images straight from exportLinks
+//	bytesWritten, err := client.ExportWorkspaceDocumentWithOptions(ctx, deckID, out,
+//	    gdrive.ExportFormatPPTX, gdrive.ExportOptions{UseExportLinks: true})
+func (dc *DriveClient) ExportWorkspaceDocumentWithOptions(ctx context.Context, fileID string, w io.Writer, format ExportFormat, opts ExportOptions) (int64, error) {
 	if fileID == "" {
 		return 0, errors.New("file ID cannot be empty")
 	}
@@ -930,7 +1299,59 @@ func (dc *DriveClient) ExportWorkspaceDocument(ctx context.Context, fileID strin
 		return 0, errors.New("export format cannot be empty")
 	}
 
+	if c := dc.contentCache.Load(); c != nil {
+		return c.exportFile(ctx, fileID, format, w, func(w io.Writer) (int64, error) {
+			return dc.exportDocument(ctx, fileID, w, format, opts)
+		})
+	}
+	return dc.exportDocument(ctx, fileID, w, format, opts)
+}
+
+// exportDocument exports a Workspace document to w without the content cache.
+func (dc *DriveClient) exportDocument(ctx context.Context, fileID string, w io.Writer, format ExportFormat, opts ExportOptions) (int64, error) {
+	if opts.UseExportLinks {
+		return dc.exportViaLink(ctx, fileID, w, format)
+	}
+
 	resp, err := dc.service.Files.Export(fileID, string(format)).Context(ctx).Download()
+	if err != nil {
+		if isExportSizeLimitError(err) {
+			return dc.exportViaLink(ctx, fileID, w, format)
+		}
+		return 0, fmt.Errorf("unable to export document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	written, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return written, fmt.Errorf("unable to write exported content: %w", err)
+	}
+	return written, nil
+}
+
+// exportViaLink downloads an export from the file's exportLinks URL
+// using the authenticated HTTP client.
+func (dc *DriveClient) exportViaLink(ctx context.Context, fileID string, w io.Writer, format ExportFormat) (int64, error) {
+	links, err := dc.GetExportLinks(ctx, fileID)
+	if err != nil {
+		return 0, err
+	}
+
+	link, ok := links[string(format)]
+	if !ok {
+		return 0, fmt.Errorf("export format %s is not available for this document", format)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return 0, fmt.Errorf("unable to create export request: %w", err)
+	}
+
+	resp, err := dc.httpClient.Do(req)
 	if err != nil {
 		return 0, fmt.Errorf("unable to export document: %w", err)
 	}
@@ -947,6 +1368,21 @@ func (dc *DriveClient) ExportWorkspaceDocument(ctx context.Context, fileID strin
 	return written, nil
 }
 
+// isExportSizeLimitError reports whether err is Drive's rejection of an export
+// that exceeds the Files.Export size limit.
+func isExportSizeLimitError(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden {
+		return false
+	}
+	for _, item := range apiErr.Errors {
+		if item.Reason == "exportSizeLimitExceeded" {
+			return true
+		}
+	}
+	return strings.Contains(apiErr.Message, "too large to be exported")
+}
+
 // ExportWorkspaceDocumentToFile exports a Google Workspace document to a local file.
 // This is a convenience method that wraps ExportWorkspaceDocument.
 // The parent directory is created automatically if it doesn't exist.
@@ -1016,10 +1452,7 @@ func (dc *DriveClient) GetExportLinks(ctx context.Context, fileID string) (map[s
 		return nil, errors.New("file ID cannot be empty")
 	}
 
-	file, err := dc.service.Files.Get(fileID).
-		Context(ctx).
-		Fields("exportLinks, mimeType").
-		Do()
+	file, err := dc.getFileMetadata(ctx, fileID, "exportLinks, mimeType")
 	if err != nil {
 		return nil, fmt.Errorf("unable to get file metadata: %w", err)
 	}
@@ -1027,7 +1460,8 @@ func (dc *DriveClient) GetExportLinks(ctx context.Context, fileID string) (map[s
 	if len(file.ExportLinks) == 0 {
 		return nil, fmt.Errorf("file is not a Google Workspace document (MIME type: %s)", file.MimeType)
 	}
-	return file.ExportLinks, nil
+	// The map may belong to the metadata cache; callers get their own copy.
+	return maps.Clone(file.ExportLinks), nil
 }
 
 // DownloadRevision downloads a specific revision of a file.
@@ -1161,10 +1595,7 @@ func (dc *DriveClient) IsWorkspaceDocument(ctx context.Context, fileID string) (
 		return false, errors.New("file ID cannot be empty")
 	}
 
-	file, err := dc.service.Files.Get(fileID).
-		Context(ctx).
-		Fields("mimeType").
-		Do()
+	file, err := dc.getFileMetadata(ctx, fileID, "mimeType")
 	if err != nil {
 		return false, fmt.Errorf("unable to get file metadata: %w", err)
 	}
@@ -0,0 +1,111 @@
+package gdrive_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abiiranathan/gdrive"
+	"github.com/abiiranathan/gdrive/gdrivetest"
+)
+
+// newClient starts a fake and returns it with a DriveClient wired to it.
+func newClient(t *testing.T) (*gdrivetest.Server, *gdrive.DriveClient) {
+	t.Helper()
+	srv := gdrivetest.NewServer()
+	t.Cleanup(srv.Close)
+	client, err := srv.Client(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, client
+}
+
+// countRequests returns how many logged requests equal method and path.
+func countRequests(srv *gdrivetest.Server, method, path string) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r == method+" "+path {
+			n++
+		}
+	}
+	return n
+}
+
+// pattern returns n bytes that differ from one offset to the next,
+// so misplaced ranges are detected.
+func pattern(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestDownloadFileResume(t *testing.T) {
+	srv, client := newClient(t)
+	ctx := context.Background()
+	content := pattern(10000)
+	id := srv.AddFile("data.bin", "", "", content)
+	out := filepath.Join(t.TempDir(), "out", "data.bin")
+
+	// The metadata response fits in the first 2000 bytes; the content does not.
+	srv.InjectFault(gdrivetest.Fault{Path: "/files/" + id, TruncateAfter: 2000})
+	if _, err := client.DownloadFile(ctx, id, out); err == nil {
+		t.Fatal("interrupted download succeeded")
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("output exists after an interrupted download: %v", err)
+	}
+	partial, err := os.ReadFile(out + ".partial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(partial) == 0 || !bytes.Equal(partial, content[:len(partial)]) {
+		t.Fatalf("partial file holds %d bytes, want a prefix of the content", len(partial))
+	}
+
+	srv.ClearFaults()
+	n, err := client.DownloadFile(ctx, id, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(content)) {
+		t.Errorf("downloaded %d bytes, want %d", n, len(content))
+	}
+	if got, _ := os.ReadFile(out); !bytes.Equal(got, content) {
+		t.Error("resumed download does not match the content")
+	}
+	if _, err := os.Stat(out + ".partial"); !os.IsNotExist(err) {
+		t.Errorf("partial file left behind: %v", err)
+	}
+}
+
+func TestDownloadFileDiscardsCorruptPartial(t *testing.T) {
+	srv, client := newClient(t)
+	ctx := context.Background()
+	content := pattern(1000)
+	id := srv.AddFile("data.bin", "", "", content)
+	out := filepath.Join(t.TempDir(), "data.bin")
+
+	// A partial file that does not match the remote content fails verification.
+	if err := os.WriteFile(out+".partial", bytes.Repeat([]byte{0xff}, 500), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DownloadFile(ctx, id, out); !errors.Is(err, gdrive.ErrChecksumMismatch) {
+		t.Fatalf("download over a corrupt partial file: %v, want ErrChecksumMismatch", err)
+	}
+	if _, err := os.Stat(out + ".partial"); !os.IsNotExist(err) {
+		t.Errorf("corrupt partial file kept: %v", err)
+	}
+
+	if _, err := client.DownloadFile(ctx, id, out); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(out); !bytes.Equal(got, content) {
+		t.Error("download after discarding the partial file does not match the content")
+	}
+}
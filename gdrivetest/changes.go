@@ -0,0 +1,155 @@
+package gdrivetest
+
+import (
+	"net/http"
+	"strconv"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// maxUploadSize is the maxUploadSize reported by the about endpoint (5 TiB).
+const maxUploadSize = 5 << 40
+
+// importFormats is the importFormats table reported by the about endpoint.
+var importFormats = map[string][]string{
+	"text/plain":                {"application/vnd.google-apps.document"},
+	"text/html":                 {"application/vnd.google-apps.document"},
+	"text/markdown":             {"application/vnd.google-apps.document"},
+	"application/rtf":           {"application/vnd.google-apps.document"},
+	"application/msword":        {"application/vnd.google-apps.document"},
+	"text/csv":                  {"application/vnd.google-apps.spreadsheet"},
+	"text/tab-separated-values": {"application/vnd.google-apps.spreadsheet"},
+	"application/vnd.ms-excel":  {"application/vnd.google-apps.spreadsheet"},
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   {"application/vnd.google-apps.document"},
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         {"application/vnd.google-apps.spreadsheet"},
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": {"application/vnd.google-apps.presentation"},
+}
+
+// exportFormats is the exportFormats table reported by the about endpoint and
+// used to validate exports. It mirrors Drive's own table; the fake keeps a copy
+// so that tests do not depend on the table of the package under test.
+var exportFormats = map[string][]string{
+	"application/vnd.google-apps.document": {
+		"application/rtf",
+		"application/vnd.oasis.opendocument.text",
+		"text/html",
+		"application/pdf",
+		"text/markdown",
+		"application/epub+zip",
+		"application/zip",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"text/plain",
+	},
+	"application/vnd.google-apps.spreadsheet": {
+		"text/tab-separated-values",
+		"application/pdf",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"text/csv",
+		"application/zip",
+		"application/vnd.oasis.opendocument.spreadsheet",
+	},
+	"application/vnd.google-apps.presentation": {
+		"application/vnd.oasis.opendocument.presentation",
+		"application/pdf",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		"text/plain",
+		"image/jpeg",
+		"image/png",
+		"image/svg+xml",
+	},
+	"application/vnd.google-apps.drawing": {"image/svg+xml", "image/png", "application/pdf", "image/jpeg"},
+	"application/vnd.google-apps.script":  {"application/vnd.google-apps.script+json"},
+	"application/vnd.google-apps.jam":     {"application/pdf"},
+}
+
+// handleStartPageToken serves changes.getStartPageToken.
+// Page tokens are 1-based positions in the change log.
+func (s *Server) handleStartPageToken(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	token := strconv.Itoa(len(s.changes) + 1)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, &drive.StartPageToken{Kind: "drive#startPageToken", StartPageToken: token})
+}
+
+// handleListChanges serves changes.list. The last page carries newStartPageToken.
+func (s *Server) handleListChanges(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := strconv.Atoi(q.Get("pageToken"))
+	if err != nil || token < 1 || token > len(s.changes)+1 {
+		writeError(w, &apiError{code: http.StatusBadRequest, reason: "invalid", message: "Invalid Value: pageToken " + q.Get("pageToken")})
+		return
+	}
+	pageSize := 100
+	if v, err := strconv.Atoi(q.Get("pageSize")); err == nil && v > 0 {
+		pageSize = min(v, 1000)
+	}
+	includeRemoved := q.Get("includeRemoved") != "false"
+
+	list := &drive.ChangeList{Kind: "drive#changeList", Changes: []*drive.Change{}}
+	start := token - 1
+	end := min(start+pageSize, len(s.changes))
+	for _, c := range s.changes[start:end] {
+		if c.removed && !includeRemoved {
+			continue
+		}
+		change := &drive.Change{
+			Kind:       "drive#change",
+			ChangeType: "file",
+			FileId:     c.fileID,
+			Removed:    c.removed,
+			Time:       c.time,
+		}
+		if f, ok := s.files[c.fileID]; ok && !c.removed {
+			change.File = s.view(f)
+		}
+		list.Changes = append(list.Changes, change)
+	}
+	if end < len(s.changes) {
+		list.NextPageToken = strconv.Itoa(end + 1)
+	} else {
+		list.NewStartPageToken = strconv.Itoa(len(s.changes) + 1)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// usage returns the bytes used by binary content in total and in the trash.
+func (s *Server) usage() (total, trash int64) {
+	for _, f := range s.files {
+		if isWorkspace(f.meta.MimeType) {
+			continue
+		}
+		size := int64(len(f.content))
+		total += size
+		if s.isTrashed(f) {
+			trash += size
+		}
+	}
+	return total, trash
+}
+
+// handleAbout serves about.get.
+func (s *Server) handleAbout(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	total, trash := s.usage()
+	limit := s.storageLimit
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, &drive.About{
+		Kind: "drive#about",
+		User: fakeUser,
+		StorageQuota: &drive.AboutStorageQuota{
+			Limit:             limit,
+			Usage:             total,
+			UsageInDrive:      total,
+			UsageInDriveTrash: trash,
+		},
+		MaxUploadSize: maxUploadSize,
+		ImportFormats: importFormats,
+		ExportFormats: exportFormats,
+	})
+}
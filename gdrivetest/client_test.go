@@ -0,0 +1,256 @@
+package gdrivetest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/abiiranathan/gdrive"
+	"github.com/abiiranathan/gdrive/gdrivetest"
+	"google.golang.org/api/drive/v3"
+)
+
+// newClient starts a fake and returns it with a DriveClient wired to it.
+func newClient(t *testing.T) (*gdrivetest.Server, *gdrive.DriveClient) {
+	t.Helper()
+	srv := gdrivetest.NewServer()
+	t.Cleanup(srv.Close)
+	client, err := srv.Client(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, client
+}
+
+// countRequests returns how many logged requests equal method and path.
+func countRequests(srv *gdrivetest.Server, method, path string) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r == method+" "+path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestStreamFile(t *testing.T) {
+	srv, client := newClient(t)
+	ctx := context.Background()
+	content := bytes.Repeat([]byte("gdrive"), 1000)
+	id := srv.AddFile("data.bin", "", "", content)
+
+	var buf bytes.Buffer
+	n, err := client.StreamFile(ctx, id, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(content)) || !bytes.Equal(buf.Bytes(), content) {
+		t.Errorf("streamed %d bytes, want %d", n, len(content))
+	}
+
+	if _, err := client.StreamFile(ctx, "missing", &buf); err == nil {
+		t.Error("streaming a missing file succeeded")
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	srv, client := newClient(t)
+	projects := srv.AddFolder("Projects", "")
+	year := srv.AddFolder("2024", projects)
+	srv.AddFile("plan.txt", year, "text/plain", []byte("plan"))
+	srv.AddFile("readme.txt", "", "text/plain", []byte("readme"))
+	srv.AddFile("empty.txt", "", "text/plain", nil)
+	srv.AddWorkspaceDocument("Doc", projects, gdrive.WorkspaceDocument, nil)
+
+	files, err := client.ListFiles(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, f := range files {
+		got[f.Name] = f.FolderPath
+	}
+	want := map[string]string{
+		"plan.txt":   "My Drive/Projects/2024",
+		"readme.txt": "My Drive",
+	}
+	if len(got) != len(want) {
+		t.Errorf("listed %v, want %v", got, want)
+	}
+	for name, path := range want {
+		if got[name] != path {
+			t.Errorf("%s: FolderPath %q, want %q", name, got[name], path)
+		}
+	}
+}
+
+func TestUploadOrReplace(t *testing.T) {
+	srv, client := newClient(t)
+	ctx := context.Background()
+	folder := srv.AddFolder("Out", "")
+	path := filepath.Join(t.TempDir(), "report.txt")
+
+	if err := os.WriteFile(path, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	first, created, err := client.UploadOrReplace(ctx, path, "", folder, gdrive.UpsertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || first.Name != "report.txt" {
+		t.Fatalf("first upload: created=%v name=%q", created, first.Name)
+	}
+
+	// A Workspace document with the same name is not a match.
+	srv.AddWorkspaceDocument("report.txt", folder, gdrive.WorkspaceDocument, nil)
+
+	if err := os.WriteFile(path, []byte("v2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second, created, err := client.UploadOrReplace(ctx, path, "", folder, gdrive.UpsertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || second.ID != first.ID {
+		t.Errorf("second upload: created=%v id=%s, want an update of %s", created, second.ID, first.ID)
+	}
+	if content, _ := srv.Content(first.ID); string(content) != "v2" {
+		t.Errorf("content %q, want v2", content)
+	}
+
+	srv.AddFile("report.txt", folder, "text/plain", []byte("copy"))
+	_, _, err = client.UploadOrReplace(ctx, path, "", folder, gdrive.UpsertOptions{})
+	if !errors.Is(err, gdrive.ErrDuplicateFiles) {
+		t.Errorf("upload with duplicates: %v, want ErrDuplicateFiles", err)
+	}
+}
+
+func TestUploadOrReplaceQuota(t *testing.T) {
+	srv, client := newClient(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(path, make([]byte, 1000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetStorageLimit(100)
+
+	_, _, err := client.UploadOrReplace(ctx, path, "", "", gdrive.UpsertOptions{})
+	var quotaErr *gdrive.QuotaError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("upload over quota: %v, want a QuotaError", err)
+	}
+	if got := countRequests(srv, "POST", "/upload/drive/v3/files"); got != 0 {
+		t.Errorf("%d uploads sent after a failed quota check", got)
+	}
+}
+
+func TestMetadataCache(t *testing.T) {
+	srv, client := newClient(t)
+	ctx := context.Background()
+	id := srv.AddFile("a.txt", "", "text/plain", []byte("a"))
+
+	cache, err := client.EnableMetadataCache(ctx, gdrive.MetadataCacheOptions{PollInterval: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+
+	srv.ResetRequests()
+	for range 3 {
+		info, err := client.GetFile(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Name != "a.txt" {
+			t.Fatalf("name %q, want a.txt", info.Name)
+		}
+	}
+	if got := countRequests(srv, "GET", "/drive/v3/files/"+id); got != 1 {
+		t.Errorf("%d metadata requests for 3 lookups, want 1", got)
+	}
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("stats %+v, want 2 hits and 1 miss", stats)
+	}
+
+	// A change made elsewhere is picked up by Sync.
+	srv.Update(id, func(f *drive.File) { f.Name = "b.txt" })
+	if err := cache.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	info, err := client.GetFile(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "b.txt" {
+		t.Errorf("name after Sync %q, want b.txt", info.Name)
+	}
+	if stats := cache.Stats(); stats.Invalidations == 0 {
+		t.Errorf("stats %+v, want an invalidation", stats)
+	}
+}
+
+func TestContentCache(t *testing.T) {
+	srv, client := newClient(t)
+	ctx := context.Background()
+	id := srv.AddFile("a.txt", "", "text/plain", []byte("first"))
+	doc := srv.AddWorkspaceDocument("Doc", "", gdrive.WorkspaceDocument, map[gdrive.ExportFormat][]byte{
+		gdrive.ExportFormatPDF: []byte("%PDF"),
+	})
+
+	cache, err := client.EnableContentCache(gdrive.ContentCacheOptions{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+
+	stream := func() string {
+		t.Helper()
+		var buf bytes.Buffer
+		if _, err := client.StreamFile(ctx, id, &buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	srv.ResetRequests()
+	if got := stream(); got != "first" {
+		t.Fatalf("first download %q", got)
+	}
+	if got := stream(); got != "first" {
+		t.Fatalf("cached download %q", got)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("stats %+v, want 1 hit, 1 miss and 1 entry", stats)
+	}
+	// The miss looks up the key, downloads and checks the key again;
+	// the hit only looks up the key.
+	if got := slices.DeleteFunc(srv.Requests(), func(r string) bool { return !strings.HasSuffix(r, "/files/"+id) }); len(got) != 4 {
+		t.Errorf("requests %q, want 4", got)
+	}
+
+	// New content changes the checksum, so the cached body is not served.
+	if _, err := client.UpdateFileContent(ctx, id, strings.NewReader("second"), gdrive.UpdateContentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := stream(); got != "second" {
+		t.Errorf("download after an update %q, want second", got)
+	}
+
+	for range 2 {
+		var buf bytes.Buffer
+		if _, err := client.ExportWorkspaceDocument(ctx, doc, &buf, gdrive.ExportFormatPDF); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != "%PDF" {
+			t.Errorf("export %q, want %%PDF", buf.String())
+		}
+	}
+	if got := countRequests(srv, "GET", "/drive/v3/files/"+doc+"/export"); got != 1 {
+		t.Errorf("%d export requests for 2 exports, want 1", got)
+	}
+}
@@ -0,0 +1,710 @@
+package gdrivetest
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// fakeUser is the account the fake acts as.
+var fakeUser = &drive.User{
+	Kind:         "drive#user",
+	DisplayName:  "Test User",
+	EmailAddress: "test.user@example.com",
+	Me:           true,
+}
+
+// readOnlyFields are file fields that update requests cannot change.
+var readOnlyFields = map[string]bool{
+	"id": true, "kind": true, "size": true, "md5Checksum": true, "headRevisionId": true,
+	"version": true, "createdTime": true, "exportLinks": true, "webViewLink": true,
+	"thumbnailLink": true, "trashedTime": true, "trashingUser": true, "explicitlyTrashed": true,
+	"ownedByMe": true, "owners": true, "capabilities": true, "contentHints": true,
+}
+
+// routes registers the API handlers.
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /drive/v3/files", s.handleList)
+	s.mux.HandleFunc("POST /drive/v3/files", s.handleCreate)
+	s.mux.HandleFunc("DELETE /drive/v3/files/trash", s.handleEmptyTrash)
+	s.mux.HandleFunc("GET /drive/v3/files/{id}", s.handleGet)
+	s.mux.HandleFunc("PATCH /drive/v3/files/{id}", s.handleUpdate)
+	s.mux.HandleFunc("DELETE /drive/v3/files/{id}", s.handleDelete)
+	s.mux.HandleFunc("GET /drive/v3/files/{id}/export", s.handleExport)
+
+	s.mux.HandleFunc("POST /upload/drive/v3/files", s.handleUpload)
+	s.mux.HandleFunc("PATCH /upload/drive/v3/files/{id}", s.handleUpload)
+	s.mux.HandleFunc("POST /upload/drive/v3/sessions/{session}", s.handleUploadChunk)
+	s.mux.HandleFunc("PUT /upload/drive/v3/sessions/{session}", s.handleUploadChunk)
+
+	s.mux.HandleFunc("GET /drive/v3/files/{id}/revisions", s.handleListRevisions)
+	s.mux.HandleFunc("GET /drive/v3/files/{id}/revisions/{rev}", s.handleGetRevision)
+	s.mux.HandleFunc("PATCH /drive/v3/files/{id}/revisions/{rev}", s.handleUpdateRevision)
+	s.mux.HandleFunc("DELETE /drive/v3/files/{id}/revisions/{rev}", s.handleDeleteRevision)
+
+	s.mux.HandleFunc("GET /drive/v3/changes/startPageToken", s.handleStartPageToken)
+	s.mux.HandleFunc("GET /drive/v3/changes", s.handleListChanges)
+	s.mux.HandleFunc("GET /drive/v3/about", s.handleAbout)
+	s.mux.HandleFunc("GET /thumbnails/{name}", s.handleThumbnail)
+
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, &apiError{
+			code:    http.StatusNotImplemented,
+			reason:  "notImplemented",
+			message: "gdrivetest does not implement " + r.Method + " " + r.URL.Path,
+		})
+	})
+}
+
+// createFile stores a new file. meta is taken over by the store.
+func (s *Server) createFile(meta *drive.File, content []byte, mediaType string, keepForever bool) (*file, *apiError) {
+	if meta.MimeType == "" {
+		meta.MimeType = mediaTypeOnly(mediaType)
+	}
+	if meta.MimeType == "" {
+		meta.MimeType = "application/octet-stream"
+	}
+	if meta.Name == "" {
+		meta.Name = "Untitled"
+	}
+	if len(meta.Parents) == 0 {
+		meta.Parents = []string{RootID}
+	}
+	for _, parentID := range meta.Parents {
+		if apiErr := s.checkFolder(parentID); apiErr != nil {
+			return nil, apiErr
+		}
+	}
+	if meta.MimeType == shortcutMimeType {
+		if meta.ShortcutDetails == nil || meta.ShortcutDetails.TargetId == "" {
+			return nil, badRequest("A shortcut must specify shortcutDetails.targetId.")
+		}
+		target, ok := s.files[meta.ShortcutDetails.TargetId]
+		if !ok {
+			return nil, notFound(meta.ShortcutDetails.TargetId)
+		}
+		meta.ShortcutDetails.TargetMimeType = target.meta.MimeType
+	}
+	if apiErr := s.checkStorage(meta.MimeType, int64(len(content))); apiErr != nil {
+		return nil, apiErr
+	}
+
+	now := s.now()
+	meta.Kind = "drive#file"
+	meta.Id = s.newID("f")
+	meta.CreatedTime = now
+	if meta.ModifiedTime == "" {
+		meta.ModifiedTime = now
+	}
+	meta.Version = 1
+	meta.OwnedByMe = true
+	meta.Trashed, meta.ExplicitlyTrashed = false, false
+
+	f := &file{meta: meta, seq: s.seq, exports: make(map[string][]byte)}
+	s.setThumbnail(f, meta.ContentHints)
+	meta.ContentHints = nil
+	if meta.MimeType != folderMimeType && meta.MimeType != shortcutMimeType {
+		s.setContent(f, content, keepForever)
+	}
+
+	s.files[meta.Id] = f
+	s.recordChange(meta.Id, false)
+	return f, nil
+}
+
+// updateFile applies a metadata patch and, if hasMedia is set, new content.
+func (s *Server) updateFile(id string, raw []byte, hasMedia bool, content []byte, mediaType string, query url.Values) (*file, *apiError) {
+	f, ok := s.files[id]
+	if !ok {
+		return nil, notFound(id)
+	}
+
+	var patch map[string]json.RawMessage
+	if len(bytes.TrimSpace(raw)) > 0 {
+		if err := json.Unmarshal(raw, &patch); err != nil {
+			return nil, badRequest("Invalid JSON payload: " + err.Error())
+		}
+	}
+
+	_, unlocking := patch["contentRestrictions"]
+	_, renaming := patch["name"]
+	_, describing := patch["description"]
+	if isLocked(f.meta) && !unlocking && (hasMedia || renaming || describing) {
+		return nil, &apiError{
+			code:    http.StatusForbidden,
+			reason:  "contentRestricted",
+			message: "The file is locked and cannot be modified.",
+		}
+	}
+	if hasMedia && !isWorkspace(f.meta.MimeType) {
+		if apiErr := s.checkStorage(f.meta.MimeType, int64(len(content)-len(f.content))); apiErr != nil {
+			return nil, apiErr
+		}
+	}
+
+	if apiErr := s.applyPatch(f, patch); apiErr != nil {
+		return nil, apiErr
+	}
+	if apiErr := s.moveFile(f, query.Get("addParents"), query.Get("removeParents")); apiErr != nil {
+		return nil, apiErr
+	}
+
+	if raw, ok := patch["contentHints"]; ok {
+		var hints drive.FileContentHints
+		if json.Unmarshal(raw, &hints) == nil {
+			s.setThumbnail(f, &hints)
+		}
+	}
+	if hasMedia {
+		if _, ok := patch["mimeType"]; !ok && mediaType != "" && !isWorkspace(f.meta.MimeType) {
+			f.meta.MimeType = mediaTypeOnly(mediaType)
+		}
+		s.setContent(f, content, query.Get("keepRevisionForever") == "true")
+	}
+	s.touch(f)
+	return f, nil
+}
+
+// applyPatch merges a JSON metadata patch into f. Null values clear fields;
+// properties and appProperties are merged per key.
+func (s *Server) applyPatch(f *file, patch map[string]json.RawMessage) *apiError {
+	if len(patch) == 0 {
+		return nil
+	}
+
+	encoded, _ := json.Marshal(f.meta)
+	var current map[string]json.RawMessage
+	json.Unmarshal(encoded, &current)
+
+	var trashed *bool
+	for key, value := range patch {
+		isNull := string(value) == "null"
+		switch {
+		case readOnlyFields[key]:
+			continue
+		case key == "parents":
+			return badRequest("The parents field is not directly writable in update requests. Use the addParents and removeParents parameters instead.")
+		case key == "trashed":
+			var b bool
+			if !isNull {
+				if err := json.Unmarshal(value, &b); err != nil {
+					return badRequest("Invalid value for trashed.")
+				}
+			}
+			trashed = &b
+			continue
+		case key == "properties" || key == "appProperties":
+			merged := make(map[string]string)
+			json.Unmarshal(current[key], &merged)
+			var changes map[string]*string
+			if !isNull {
+				if err := json.Unmarshal(value, &changes); err != nil {
+					return badRequest("Invalid value for " + key + ".")
+				}
+			}
+			for k, v := range changes {
+				if v == nil {
+					delete(merged, k)
+				} else {
+					merged[k] = *v
+				}
+			}
+			if len(merged) == 0 {
+				delete(current, key)
+			} else {
+				current[key], _ = json.Marshal(merged)
+			}
+			continue
+		}
+		if isNull {
+			delete(current, key)
+		} else {
+			current[key] = value
+		}
+	}
+
+	encoded, _ = json.Marshal(current)
+	meta := &drive.File{}
+	if err := json.Unmarshal(encoded, meta); err != nil {
+		return badRequest("Invalid file metadata: " + err.Error())
+	}
+	if meta.Name == "" {
+		return badRequest("The file name cannot be empty.")
+	}
+	f.meta = meta
+
+	if trashed != nil {
+		f.meta.Trashed, f.meta.ExplicitlyTrashed = *trashed, *trashed
+		f.meta.TrashedTime, f.meta.TrashingUser = "", nil
+		if *trashed {
+			f.meta.TrashedTime = s.now()
+			f.meta.TrashingUser = fakeUser
+		}
+	}
+	return nil
+}
+
+// moveFile adds and removes comma-separated parent IDs.
+func (s *Server) moveFile(f *file, addParents, removeParents string) *apiError {
+	for id := range strings.SplitSeq(addParents, ",") {
+		if id == "" || slices.Contains(f.meta.Parents, id) {
+			continue
+		}
+		if apiErr := s.checkFolder(id); apiErr != nil {
+			return apiErr
+		}
+		f.meta.Parents = append(f.meta.Parents, id)
+	}
+	for id := range strings.SplitSeq(removeParents, ",") {
+		if id != "" {
+			f.meta.Parents = slices.DeleteFunc(f.meta.Parents, func(p string) bool { return p == id })
+		}
+	}
+	return nil
+}
+
+// checkFolder reports an error unless id is an existing folder.
+func (s *Server) checkFolder(id string) *apiError {
+	parent, ok := s.files[id]
+	if !ok {
+		return notFound(id)
+	}
+	if parent.meta.MimeType != folderMimeType {
+		return badRequest("The parent " + id + " is not a folder.")
+	}
+	return nil
+}
+
+// checkStorage rejects binary content that would exceed the storage quota.
+func (s *Server) checkStorage(mimeType string, growth int64) *apiError {
+	if s.storageLimit <= 0 || isWorkspace(mimeType) || growth <= 0 {
+		return nil
+	}
+	usage, _ := s.usage()
+	if usage+growth > s.storageLimit {
+		return &apiError{
+			code:    http.StatusForbidden,
+			reason:  "storageQuotaExceeded",
+			message: "The user's Drive storage quota has been exceeded.",
+		}
+	}
+	return nil
+}
+
+// setContent replaces the content of f and adds a head revision.
+func (s *Server) setContent(f *file, content []byte, keepForever bool) {
+	f.content = content
+	rev := &drive.Revision{
+		Kind:             "drive#revision",
+		Id:               s.newID("r"),
+		MimeType:         f.meta.MimeType,
+		ModifiedTime:     s.now(),
+		KeepForever:      keepForever,
+		OriginalFilename: f.meta.Name,
+		LastModifyingUser: &drive.User{
+			DisplayName:  fakeUser.DisplayName,
+			EmailAddress: fakeUser.EmailAddress,
+			Me:           true,
+		},
+	}
+	if !isWorkspace(f.meta.MimeType) {
+		f.meta.Size = int64(len(content))
+		f.meta.Md5Checksum = md5Hex(content)
+		f.meta.HeadRevisionId = rev.Id
+		rev.Size = f.meta.Size
+		rev.Md5Checksum = f.meta.Md5Checksum
+		if f.thumbnail == nil && strings.HasPrefix(f.meta.MimeType, "image/") && len(content) > 0 {
+			f.thumbnail, f.thumbnailType = content, f.meta.MimeType
+		}
+	}
+	f.revisions = append(f.revisions, &revision{meta: rev, content: content})
+}
+
+// setThumbnail stores a custom thumbnail from upload content hints.
+func (s *Server) setThumbnail(f *file, hints *drive.FileContentHints) {
+	if hints == nil || hints.Thumbnail == nil || hints.Thumbnail.Image == "" {
+		return
+	}
+	image, err := base64.URLEncoding.DecodeString(hints.Thumbnail.Image)
+	if err != nil {
+		image, err = base64.StdEncoding.DecodeString(hints.Thumbnail.Image)
+	}
+	if err != nil {
+		return
+	}
+	f.thumbnail, f.thumbnailType = image, hints.Thumbnail.MimeType
+	if f.thumbnailType == "" {
+		f.thumbnailType = http.DetectContentType(image)
+	}
+}
+
+// remove deletes f and everything below it, recording the removals.
+func (s *Server) remove(f *file) {
+	delete(s.files, f.meta.Id)
+	s.recordChange(f.meta.Id, true)
+	for _, child := range s.children(f.meta.Id) {
+		if _, ok := s.files[child.meta.Id]; ok {
+			s.remove(child)
+		}
+	}
+}
+
+// children returns the files with parentID among their parents, in creation order.
+func (s *Server) children(parentID string) []*file {
+	var result []*file
+	for _, f := range s.files {
+		if slices.Contains(f.meta.Parents, parentID) {
+			result = append(result, f)
+		}
+	}
+	slices.SortFunc(result, func(a, b *file) int { return cmp.Compare(a.seq, b.seq) })
+	return result
+}
+
+// isTrashed reports whether f or one of its ancestors is in the trash.
+func (s *Server) isTrashed(f *file) bool {
+	seen := make(map[string]bool)
+	for f != nil && !seen[f.meta.Id] {
+		if f.meta.Trashed {
+			return true
+		}
+		seen[f.meta.Id] = true
+		if len(f.meta.Parents) == 0 {
+			break
+		}
+		f = s.files[f.meta.Parents[0]]
+	}
+	return false
+}
+
+// isLocked reports whether a read-only content restriction is in effect.
+func isLocked(meta *drive.File) bool {
+	for _, r := range meta.ContentRestrictions {
+		if r != nil && r.ReadOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// view returns a copy of the metadata of f as the API returns it.
+func (s *Server) view(f *file) *drive.File {
+	encoded, _ := json.Marshal(f.meta)
+	meta := &drive.File{}
+	json.Unmarshal(encoded, meta)
+
+	meta.Trashed = s.isTrashed(f)
+	switch {
+	case meta.MimeType == folderMimeType:
+		meta.WebViewLink = "https://drive.google.com/drive/folders/" + meta.Id
+	case isWorkspace(meta.MimeType):
+		meta.WebViewLink = "https://docs.google.com/open?id=" + meta.Id
+		meta.ExportLinks = exportLinks(meta.Id, meta.MimeType)
+	default:
+		meta.WebViewLink = "https://drive.google.com/file/d/" + meta.Id + "/view"
+	}
+	if f.thumbnail != nil {
+		meta.ThumbnailLink = apiBase + "/thumbnails/" + meta.Id + "=s220"
+	}
+	return meta
+}
+
+// exportLinks returns the export links of a Workspace document, or nil if it cannot be exported.
+func exportLinks(id, mimeType string) map[string]string {
+	formats := exportFormats[mimeType]
+	if len(formats) == 0 {
+		return nil
+	}
+	links := make(map[string]string, len(formats))
+	for _, format := range formats {
+		links[format] = apiBase + "/drive/v3/files/" + id + "/export?mimeType=" + url.QueryEscape(format)
+	}
+	return links
+}
+
+// mediaTypeOnly strips parameters from a Content-Type value.
+func mediaTypeOnly(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mediaType
+}
+
+// handleList serves files.list.
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	match, err := s.parseQuery(q.Get("q"))
+	if err != nil {
+		writeError(w, &apiError{code: http.StatusBadRequest, reason: "invalid", message: "Invalid Value: " + err.Error()})
+		return
+	}
+
+	var matched []*file
+	for _, f := range s.files {
+		if f.meta.Id != RootID && match(f) {
+			matched = append(matched, f)
+		}
+	}
+	if apiErr := sortFiles(matched, q.Get("orderBy")); apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	start, end, next, apiErr := page(len(matched), q.Get("pageToken"), q.Get("pageSize"), 100, 1000)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	list := &drive.FileList{Kind: "drive#fileList", NextPageToken: next, Files: []*drive.File{}}
+	for _, f := range matched[start:end] {
+		list.Files = append(list.Files, s.view(f))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// sortFiles orders files by a Drive orderBy expression, e.g. "folder,name desc".
+// Files are ordered by creation as a last resort.
+func sortFiles(files []*file, orderBy string) *apiError {
+	type key struct {
+		field string
+		desc  bool
+	}
+	var keys []key
+	for part := range strings.SplitSeq(orderBy, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		k := key{field: fields[0], desc: len(fields) > 1 && fields[1] == "desc"}
+		switch k.field {
+		case "name", "name_natural", "modifiedTime", "createdTime", "folder", "starred", "quotaBytesUsed", "recency":
+		default:
+			return &apiError{code: http.StatusBadRequest, reason: "invalid", message: "Invalid Value: orderBy " + k.field}
+		}
+		keys = append(keys, k)
+	}
+
+	slices.SortStableFunc(files, func(a, b *file) int {
+		for _, k := range keys {
+			var c int
+			switch k.field {
+			case "name", "name_natural":
+				c = cmp.Compare(strings.ToLower(a.meta.Name), strings.ToLower(b.meta.Name))
+			case "modifiedTime", "recency":
+				c = cmp.Compare(a.meta.ModifiedTime, b.meta.ModifiedTime)
+			case "createdTime":
+				c = cmp.Compare(a.meta.CreatedTime, b.meta.CreatedTime)
+			case "folder":
+				// Folders first in ascending order.
+				c = cmp.Compare(boolRank(b.meta.MimeType == folderMimeType), boolRank(a.meta.MimeType == folderMimeType))
+			case "starred":
+				c = cmp.Compare(boolRank(b.meta.Starred), boolRank(a.meta.Starred))
+			case "quotaBytesUsed":
+				c = cmp.Compare(a.meta.Size, b.meta.Size)
+			}
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.seq, b.seq)
+	})
+	return nil
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// page computes the bounds of a page of n items from offset page tokens.
+func page(n int, token, size string, defaultSize, maxSize int) (start, end int, next string, apiErr *apiError) {
+	pageSize := defaultSize
+	if size != "" {
+		v, err := strconv.Atoi(size)
+		if err != nil || v <= 0 {
+			return 0, 0, "", &apiError{code: http.StatusBadRequest, reason: "invalid", message: "Invalid Value: pageSize " + size}
+		}
+		pageSize = min(v, maxSize)
+	}
+	if token != "" {
+		v, err := strconv.Atoi(token)
+		if err != nil || v < 0 || v > n {
+			return 0, 0, "", &apiError{code: http.StatusBadRequest, reason: "invalid", message: "Invalid Value: pageToken " + token}
+		}
+		start = v
+	}
+	end = min(start+pageSize, n)
+	if end < n {
+		next = strconv.Itoa(end)
+	}
+	return start, end, next, nil
+}
+
+// handleGet serves files.get, including alt=media downloads.
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	f, ok := s.files[id]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, notFound(id))
+		return
+	}
+	if r.URL.Query().Get("alt") != "media" {
+		meta := s.view(f)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, meta)
+		return
+	}
+	mimeType, modified, content := f.meta.MimeType, f.meta.ModifiedTime, f.content
+	s.mu.Unlock()
+
+	if isWorkspace(mimeType) {
+		writeError(w, &apiError{
+			code:    http.StatusForbidden,
+			reason:  "fileNotDownloadable",
+			message: "Only files with binary content can be downloaded. Use Export with Docs Editors files.",
+		})
+		return
+	}
+	serveContent(w, r, mimeType, modified, content)
+}
+
+// serveContent writes content with support for Range requests.
+func serveContent(w http.ResponseWriter, r *http.Request, mimeType, modified string, content []byte) {
+	modTime, _ := time.Parse(time.RFC3339, modified)
+	w.Header().Set("Content-Type", mimeType)
+	http.ServeContent(w, r, "", modTime, bytes.NewReader(content))
+}
+
+// handleExport serves files.export.
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	format := r.URL.Query().Get("mimeType")
+
+	s.mu.Lock()
+	f, ok := s.files[id]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, notFound(id))
+		return
+	}
+	mimeType := f.meta.MimeType
+	content, ok := f.exports[format]
+	if !ok {
+		content = f.content
+	}
+	s.mu.Unlock()
+
+	formats := exportFormats[mimeType]
+	switch {
+	case len(formats) == 0:
+		writeError(w, &apiError{code: http.StatusForbidden, reason: "fileNotExportable", message: "Export only supports Docs Editors files."})
+	case !slices.Contains(formats, format):
+		writeError(w, badRequest("The requested conversion is not supported."))
+	case len(content) > exportSizeLimit:
+		writeError(w, &apiError{code: http.StatusForbidden, reason: "exportSizeLimitExceeded", message: "This file is too large to be exported."})
+	default:
+		w.Header().Set("Content-Type", format)
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Write(content)
+	}
+}
+
+// handleCreate serves metadata-only files.create.
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, badRequest("Unable to read request body."))
+		return
+	}
+	s.finishUpload(w, "", raw, false, nil, "", r.URL.Query())
+}
+
+// handleUpdate serves metadata-only files.update.
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, badRequest("Unable to read request body."))
+		return
+	}
+	s.finishUpload(w, r.PathValue("id"), raw, false, nil, "", r.URL.Query())
+}
+
+// handleDelete serves files.delete.
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[id]
+	if !ok {
+		writeError(w, notFound(id))
+		return
+	}
+	if id == RootID {
+		writeError(w, &apiError{code: http.StatusForbidden, reason: "insufficientFilePermissions", message: "The root folder cannot be deleted."})
+		return
+	}
+	s.remove(f)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEmptyTrash serves files.emptyTrash.
+func (s *Server) handleEmptyTrash(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.files {
+		if _, ok := s.files[f.meta.Id]; ok && f.meta.Trashed {
+			s.remove(f)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleThumbnail serves thumbnail links of the form /thumbnails/{id}=s{size}.
+func (s *Server) handleThumbnail(w http.ResponseWriter, r *http.Request) {
+	id, _, _ := strings.Cut(r.PathValue("name"), "=")
+
+	s.mu.Lock()
+	f, ok := s.files[id]
+	var image []byte
+	var mimeType string
+	if ok {
+		image, mimeType = f.thumbnail, f.thumbnailType
+	}
+	s.mu.Unlock()
+
+	if image == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(image)))
+	w.Write(image)
+}
@@ -0,0 +1,390 @@
+package gdrivetest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// predicate reports whether a file matches a search query.
+type predicate func(f *file) bool
+
+// tokenKind classifies query tokens.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits a Drive search query into tokens.
+func tokenize(q string) ([]token, error) {
+	var tokens []token
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == '{' || r == '}':
+			tokens = append(tokens, token{tokenPunct, string(r)})
+			i++
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, errors.New("unexpected '!'")
+			}
+			tokens = append(tokens, token{tokenOperator, op})
+			i += len(op)
+		case r == '\'' || r == '"':
+			var b strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, token{tokenString, b.String()})
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenWord, string(runes[start:i])})
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// queryParser is a recursive descent parser for the supported query subset:
+//
+//	expr   = and { "or" and }
+//	and    = unary { "and" unary }
+//	unary  = "not" unary | "(" expr ")" | term
+//	term   = string "in" field
+//	       | field "has" "{" "key" "=" string [ "and" "value" "=" string ] "}"
+//	       | field operator value
+type queryParser struct {
+	s      *Server
+	tokens []token
+	pos    int
+}
+
+// parseQuery compiles a Drive search query. An empty query matches every file.
+func (s *Server) parseQuery(q string) (predicate, error) {
+	if strings.TrimSpace(q) == "" {
+		return func(*file) bool { return true }, nil
+	}
+	tokens, err := tokenize(q)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{s: s, tokens: tokens}
+	match, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	return match, nil
+}
+
+func (p *queryParser) peek() token { return p.tokens[p.pos] }
+
+func (p *queryParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it has the given text.
+func (p *queryParser) accept(text string) bool {
+	if t := p.peek(); t.kind != tokenString && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) expect(text string) error {
+	if !p.accept(text) {
+		return fmt.Errorf("expected %q, got %q", text, p.peek().text)
+	}
+	return nil
+}
+
+func (p *queryParser) expectString() (string, error) {
+	t := p.next()
+	if t.kind != tokenString {
+		return "", fmt.Errorf("expected a quoted string, got %q", t.text)
+	}
+	return t.text, nil
+}
+
+func (p *queryParser) expr() (predicate, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(f *file) bool { return l(f) || right(f) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) and() (predicate, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(f *file) bool { return l(f) && right(f) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) unary() (predicate, error) {
+	if p.accept("not") {
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(f *file) bool { return !inner(f) }, nil
+	}
+	if p.accept("(") {
+		inner, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	}
+	return p.term()
+}
+
+func (p *queryParser) term() (predicate, error) {
+	first := p.next()
+	switch first.kind {
+	case tokenString:
+		if err := p.expect("in"); err != nil {
+			return nil, err
+		}
+		return p.membership(first.text, p.next().text)
+	case tokenWord:
+	default:
+		return nil, fmt.Errorf("unexpected %q", first.text)
+	}
+
+	field := first.text
+	if p.accept("has") {
+		return p.has(field)
+	}
+
+	op := p.next()
+	if op.kind != tokenOperator && op.text != "contains" {
+		return nil, fmt.Errorf("expected an operator after %s, got %q", field, op.text)
+	}
+	value := p.next()
+	switch {
+	case value.kind == tokenString:
+		return p.compareString(field, op.text, value.text)
+	case value.text == "true" || value.text == "false":
+		return p.compareBool(field, op.text, value.text == "true")
+	default:
+		return nil, fmt.Errorf("invalid value %q for %s", value.text, field)
+	}
+}
+
+// membership handles "'value' in parents" and "'me' in owners".
+func (p *queryParser) membership(value, collection string) (predicate, error) {
+	switch collection {
+	case "parents":
+		return func(f *file) bool { return slices.Contains(f.meta.Parents, value) }, nil
+	case "owners", "writers", "readers":
+		isMe := value == "me" || value == fakeUser.EmailAddress
+		return func(f *file) bool { return isMe && f.meta.OwnedByMe }, nil
+	default:
+		return nil, fmt.Errorf("unsupported collection %q", collection)
+	}
+}
+
+// has handles "properties has { key='k' and value='v' }".
+func (p *queryParser) has(field string) (predicate, error) {
+	if field != "properties" && field != "appProperties" {
+		return nil, fmt.Errorf("unsupported field %q for has", field)
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if err := p.expect("key"); err != nil {
+		return nil, err
+	}
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	key, err := p.expectString()
+	if err != nil {
+		return nil, err
+	}
+	var value *string
+	if p.accept("and") {
+		if err := p.expect("value"); err != nil {
+			return nil, err
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		v, err := p.expectString()
+		if err != nil {
+			return nil, err
+		}
+		value = &v
+	}
+	if err := p.expect("}"); err != nil {
+		return nil, err
+	}
+
+	return func(f *file) bool {
+		props := f.meta.Properties
+		if field == "appProperties" {
+			props = f.meta.AppProperties
+		}
+		v, ok := props[key]
+		return ok && (value == nil || v == *value)
+	}, nil
+}
+
+// compareString handles string and time fields.
+func (p *queryParser) compareString(field, op, value string) (predicate, error) {
+	var get func(f *file) string
+	isTime := false
+	switch field {
+	case "name":
+		get = func(f *file) string { return f.meta.Name }
+	case "mimeType":
+		get = func(f *file) string { return f.meta.MimeType }
+	case "description":
+		get = func(f *file) string { return f.meta.Description }
+	case "fullText":
+		if op != "contains" {
+			return nil, errors.New("fullText only supports contains")
+		}
+		needle := strings.ToLower(value)
+		return func(f *file) bool {
+			return strings.Contains(strings.ToLower(f.meta.Name), needle) ||
+				strings.Contains(strings.ToLower(f.meta.Description), needle) ||
+				(!isWorkspace(f.meta.MimeType) && strings.Contains(strings.ToLower(string(f.content)), needle))
+		}, nil
+	case "modifiedTime", "createdTime", "viewedByMeTime":
+		isTime = true
+		get = func(f *file) string {
+			switch field {
+			case "modifiedTime":
+				return f.meta.ModifiedTime
+			case "createdTime":
+				return f.meta.CreatedTime
+			}
+			return f.meta.ViewedByMeTime
+		}
+	default:
+		return nil, fmt.Errorf("unsupported field %q", field)
+	}
+
+	if isTime {
+		want, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q", value)
+		}
+		return func(f *file) bool {
+			got, err := time.Parse(time.RFC3339, get(f))
+			if err != nil {
+				return false
+			}
+			return compareOrdered(got.Compare(want), op)
+		}, nil
+	}
+
+	if op == "contains" {
+		needle := strings.ToLower(value)
+		return func(f *file) bool { return strings.Contains(strings.ToLower(get(f)), needle) }, nil
+	}
+	if op != "=" && op != "!=" {
+		return nil, fmt.Errorf("unsupported operator %s for %s", op, field)
+	}
+	return func(f *file) bool { return compareOrdered(strings.Compare(get(f), value), op) }, nil
+}
+
+// compareBool handles boolean fields.
+func (p *queryParser) compareBool(field, op string, value bool) (predicate, error) {
+	var get func(f *file) bool
+	switch field {
+	case "trashed":
+		get = p.s.isTrashed
+	case "starred":
+		get = func(f *file) bool { return f.meta.Starred }
+	case "sharedWithMe":
+		get = func(f *file) bool { return !f.meta.OwnedByMe }
+	default:
+		return nil, fmt.Errorf("unsupported field %q", field)
+	}
+	switch op {
+	case "=":
+		return func(f *file) bool { return get(f) == value }, nil
+	case "!=":
+		return func(f *file) bool { return get(f) != value }, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator %s for %s", op, field)
+	}
+}
+
+// compareOrdered applies op to the result of a three-way comparison.
+func compareOrdered(c int, op string) bool {
+	switch op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
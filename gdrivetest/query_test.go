@@ -0,0 +1,121 @@
+package gdrivetest
+
+import (
+	"slices"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// queryFixture creates a small tree and returns the server and file IDs by name.
+func queryFixture(t *testing.T) (*Server, map[string]string) {
+	t.Helper()
+	s := NewServer()
+	t.Cleanup(s.Close)
+
+	ids := make(map[string]string)
+	ids["Reports"] = s.AddFolder("Reports", "")
+	ids["q1 report.csv"] = s.AddFile("q1 report.csv", ids["Reports"], "text/csv", []byte("alpha,beta"))
+	ids["notes.txt"] = s.AddFile("notes.txt", "", "text/plain", []byte("Hello World"))
+	ids["old.txt"] = s.AddFile("old.txt", "", "text/plain", []byte("x"))
+	ids["it's.txt"] = s.AddFile("it's.txt", "", "text/plain", nil)
+
+	s.Update(ids["notes.txt"], func(f *drive.File) {
+		f.Starred = true
+		f.Description = "Weekly notes"
+		f.Properties = map[string]string{"team": "ops"}
+	})
+	s.Update(ids["old.txt"], func(f *drive.File) {
+		f.Trashed = true
+		f.CreatedTime = "2020-01-01T00:00:00.000Z"
+	})
+	return s, ids
+}
+
+// match returns the names of the files matching q, in name order.
+func match(t *testing.T, s *Server, q string) []string {
+	t.Helper()
+	pred, err := s.parseQuery(q)
+	if err != nil {
+		t.Fatalf("parseQuery(%q): %v", q, err)
+	}
+	var names []string
+	for id, f := range s.files {
+		if id != RootID && pred(f) {
+			names = append(names, f.meta.Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestParseQuery(t *testing.T) {
+	s, ids := queryFixture(t)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"name = 'notes.txt'", []string{"notes.txt"}},
+		{"name != 'notes.txt' and mimeType = 'text/plain'", []string{"it's.txt", "old.txt"}},
+		{"name contains 'REPORT'", []string{"Reports", "q1 report.csv"}},
+		{`name = 'it\'s.txt'`, []string{"it's.txt"}},
+		{`name = "it's.txt"`, []string{"it's.txt"}},
+		{"'" + ids["Reports"] + "' in parents", []string{"q1 report.csv"}},
+		{"mimeType = 'text/plain' and trashed = false", []string{"it's.txt", "notes.txt"}},
+		{"trashed = true", []string{"old.txt"}},
+		{"starred = true or name = 'old.txt'", []string{"notes.txt", "old.txt"}},
+		{"(name = 'old.txt' or name = 'notes.txt') and not starred = true", []string{"old.txt"}},
+		{"not not starred = true", []string{"notes.txt"}},
+		{"mimeType = 'application/vnd.google-apps.folder'", []string{"Reports"}},
+		{"mimeType != 'application/vnd.google-apps.folder' and not mimeType contains 'application/vnd.google-apps.'",
+			[]string{"it's.txt", "notes.txt", "old.txt", "q1 report.csv"}},
+		{"properties has { key='team' and value='ops' }", []string{"notes.txt"}},
+		{"properties has { key='team' and value='dev' }", nil},
+		{"properties has { key='team' }", []string{"notes.txt"}},
+		{"appProperties has { key='team' }", nil},
+		{"createdTime < '2021-01-01T00:00:00Z'", []string{"old.txt"}},
+		{"createdTime >= '2021-01-01T00:00:00Z' and name contains '.txt'", []string{"it's.txt", "notes.txt"}},
+		{"fullText contains 'hello'", []string{"notes.txt"}},
+		{"fullText contains 'weekly'", []string{"notes.txt"}},
+		{"description contains 'notes'", []string{"notes.txt"}},
+		{"'me' in owners and name = 'notes.txt'", []string{"notes.txt"}},
+		{"  ", []string{"Reports", "it's.txt", "notes.txt", "old.txt", "q1 report.csv"}},
+	}
+	for _, tt := range tests {
+		if got := match(t, s, tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("query %q matched %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	queries := []string{
+		"name = ",
+		"name = 'unterminated",
+		"name ! 'x'",
+		"name ~ 'x'",
+		"name < 'x'",
+		"size > '1'",
+		"starred < true",
+		"starred = maybe",
+		"fullText = 'x'",
+		"createdTime > 'yesterday'",
+		"(name = 'x'",
+		"name = 'x' name",
+		"name = 'x' and",
+		"'x' in nowhere",
+		"'x' parents",
+		"name has { key='a' }",
+		"properties has { value='a' }",
+		"properties has { key='a' and value='b'",
+	}
+	for _, q := range queries {
+		if _, err := s.parseQuery(q); err == nil {
+			t.Errorf("parseQuery(%q) succeeded, want an error", q)
+		}
+	}
+}
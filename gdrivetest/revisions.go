@@ -0,0 +1,154 @@
+package gdrivetest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// writableRevisionFields are revision fields that update requests can change.
+var writableRevisionFields = map[string]bool{
+	"keepForever": true, "published": true, "publishAuto": true, "publishedOutsideDomain": true,
+}
+
+// findRevision looks up a file and one of its revisions.
+func (s *Server) findRevision(fileID, revisionID string) (*file, *revision, *apiError) {
+	f, ok := s.files[fileID]
+	if !ok {
+		return nil, nil, notFound(fileID)
+	}
+	for _, rev := range f.revisions {
+		if rev.meta.Id == revisionID {
+			return f, rev, nil
+		}
+	}
+	return nil, nil, &apiError{code: http.StatusNotFound, reason: "notFound", message: "Revision not found: " + revisionID + "."}
+}
+
+// revisionView returns a copy of the metadata of rev as the API returns it.
+func revisionView(f *file, rev *revision) *drive.Revision {
+	encoded, _ := json.Marshal(rev.meta)
+	meta := &drive.Revision{}
+	json.Unmarshal(encoded, meta)
+	if isWorkspace(f.meta.MimeType) {
+		meta.ExportLinks = exportLinks(f.meta.Id, f.meta.MimeType)
+	}
+	return meta
+}
+
+// handleListRevisions serves revisions.list, oldest first.
+func (s *Server) handleListRevisions(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[id]
+	if !ok {
+		writeError(w, notFound(id))
+		return
+	}
+
+	start, end, next, apiErr := page(len(f.revisions), r.URL.Query().Get("pageToken"), r.URL.Query().Get("pageSize"), 200, 1000)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	list := &drive.RevisionList{Kind: "drive#revisionList", NextPageToken: next, Revisions: []*drive.Revision{}}
+	for _, rev := range f.revisions[start:end] {
+		list.Revisions = append(list.Revisions, revisionView(f, rev))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleGetRevision serves revisions.get, including alt=media downloads.
+func (s *Server) handleGetRevision(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	f, rev, apiErr := s.findRevision(r.PathValue("id"), r.PathValue("rev"))
+	if apiErr != nil {
+		s.mu.Unlock()
+		writeError(w, apiErr)
+		return
+	}
+	if r.URL.Query().Get("alt") != "media" {
+		meta := revisionView(f, rev)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, meta)
+		return
+	}
+	mimeType, modified, content := rev.meta.MimeType, rev.meta.ModifiedTime, rev.content
+	s.mu.Unlock()
+
+	if isWorkspace(mimeType) {
+		writeError(w, &apiError{
+			code:    http.StatusForbidden,
+			reason:  "fileNotDownloadable",
+			message: "Only revisions of files with binary content can be downloaded. Use the export links of the revision.",
+		})
+		return
+	}
+	serveContent(w, r, mimeType, modified, content)
+}
+
+// handleUpdateRevision serves revisions.update.
+func (s *Server) handleUpdateRevision(w http.ResponseWriter, r *http.Request) {
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, badRequest("Unable to read request body."))
+		return
+	}
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &patch); err != nil {
+		writeError(w, badRequest("Invalid JSON payload: "+err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, rev, apiErr := s.findRevision(r.PathValue("id"), r.PathValue("rev"))
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	encoded, _ := json.Marshal(rev.meta)
+	var current map[string]json.RawMessage
+	json.Unmarshal(encoded, &current)
+	for key, value := range patch {
+		if writableRevisionFields[key] {
+			current[key] = value
+		}
+	}
+	encoded, _ = json.Marshal(current)
+	meta := &drive.Revision{}
+	if err := json.Unmarshal(encoded, meta); err != nil {
+		writeError(w, badRequest("Invalid revision metadata: "+err.Error()))
+		return
+	}
+	rev.meta = meta
+	writeJSON(w, http.StatusOK, revisionView(f, rev))
+}
+
+// handleDeleteRevision serves revisions.delete. The head revision cannot be deleted.
+func (s *Server) handleDeleteRevision(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, rev, apiErr := s.findRevision(r.PathValue("id"), r.PathValue("rev"))
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	if rev == f.revisions[len(f.revisions)-1] {
+		writeError(w, &apiError{code: http.StatusBadRequest, reason: "cannotDeleteHeadRevision", message: "The head revision cannot be deleted."})
+		return
+	}
+
+	for i, candidate := range f.revisions {
+		if candidate == rev {
+			f.revisions = append(f.revisions[:i:i], f.revisions[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
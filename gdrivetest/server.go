@@ -0,0 +1,482 @@
+// Package gdrivetest provides an in-memory fake of the Google Drive v3 REST API
+// for testing code built on gdrive.DriveClient.
+//
+// The fake runs on an httptest.Server and implements the subset of the API used
+// by the gdrive package: files (list with a subset of the query language, get,
+// create, update, delete, media download with ranges, multipart, media and
+// resumable uploads, export, empty trash), revisions, changes, about and
+// thumbnails. Unsupported endpoints answer 501 Not Implemented.
+//
+// Requests reach the fake through an http.Client whose transport rewrites every
+// request URL to the test server, so DriveClient needs no endpoint configuration.
+// Faults such as latency, rate limiting, server errors and truncated bodies can
+// be injected per request path.
+//
+// Example:
+//
+//	func TestReport(t *testing.T) {
+//	    srv := gdrivetest.NewServer()
+//	    defer srv.Close()
+//
+//	    folder := srv.AddFolder("Reports", "")
+//	    fileID := srv.AddFile("q1.csv", folder, "text/csv", []byte("a,b\n1,2\n"))
+//
+//	    client, err := srv.Client(context.Background())
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//	    var buf bytes.Buffer
+//	    if _, err := client.StreamFile(context.Background(), fileID, &buf); err != nil {
+//	        t.Fatal(err)
+//	    }
+//	}
+package gdrivetest
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abiiranathan/gdrive"
+	"google.golang.org/api/drive/v3"
+)
+
+// RootID is the ID of the "My Drive" root folder of the fake.
+// Files created without parents are placed in it.
+const RootID = "root"
+
+// apiBase is the origin used in links returned by the fake, such as export
+// and thumbnail links. The rewriting transport sends them to the test server.
+const apiBase = "https://www.googleapis.com"
+
+// timeFormat is the RFC 3339 layout Drive uses for timestamps.
+const timeFormat = "2006-01-02T15:04:05.000Z"
+
+// Mime types with special handling.
+const (
+	folderMimeType   = "application/vnd.google-apps.folder"
+	shortcutMimeType = "application/vnd.google-apps.shortcut"
+	workspacePrefix  = "application/vnd.google-apps."
+)
+
+// DefaultStorageLimit is the storage quota of a new Server in bytes (15 GiB).
+const DefaultStorageLimit = 15 << 30
+
+// exportSizeLimit is the largest export served by the export endpoint.
+const exportSizeLimit = 10 << 20
+
+// Fault describes an error condition injected into matching requests.
+type Fault struct {
+	// Method restricts the fault to one HTTP method. Empty matches all methods.
+	Method string
+
+	// Path restricts the fault to requests whose URL path contains Path,
+	// e.g. "/files/abc" or "/export". Empty matches all paths.
+	Path string
+
+	// Latency delays the response.
+	Latency time.Duration
+
+	// Status answers with a Drive API error of this status code instead of
+	// processing the request, e.g. 429, 500 or 503.
+	Status int
+
+	// TruncateAfter aborts the response after this many body bytes,
+	// so the client sees an unexpected EOF. Zero disables truncation.
+	TruncateAfter int64
+
+	// Count is the number of matching requests the fault applies to.
+	// Zero applies it to every matching request until ClearFaults.
+	Count int
+}
+
+// Server is an in-memory fake Google Drive. It is safe for concurrent use.
+type Server struct {
+	srv *httptest.Server
+	mux *http.ServeMux
+
+	mu           sync.Mutex
+	files        map[string]*file
+	seq          int
+	changes      []change
+	uploads      map[string]*uploadSession
+	faults       []*Fault
+	requests     []string
+	storageLimit int64
+}
+
+// file is a stored Drive file with its content.
+type file struct {
+	meta          *drive.File
+	seq           int
+	content       []byte
+	exports       map[string][]byte
+	thumbnail     []byte
+	thumbnailType string
+	revisions     []*revision
+}
+
+// revision is a stored revision with its content.
+type revision struct {
+	meta    *drive.Revision
+	content []byte
+}
+
+// change is an entry of the changes feed.
+type change struct {
+	fileID  string
+	removed bool
+	time    string
+}
+
+// NewServer starts a fake Drive with an empty "My Drive" root.
+// The caller must call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		files:        make(map[string]*file),
+		uploads:      make(map[string]*uploadSession),
+		storageLimit: DefaultStorageLimit,
+	}
+	now := s.now()
+	s.files[RootID] = &file{meta: &drive.File{
+		Kind:         "drive#file",
+		Id:           RootID,
+		Name:         "My Drive",
+		MimeType:     folderMimeType,
+		CreatedTime:  now,
+		ModifiedTime: now,
+		Version:      1,
+		OwnedByMe:    true,
+	}}
+
+	s.mux = http.NewServeMux()
+	s.routes()
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// URL returns the base URL of the test server.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// HTTPClient returns an http.Client that sends every request to the test
+// server, whatever host the request URL names.
+func (s *Server) HTTPClient() *http.Client {
+	target, _ := url.Parse(s.srv.URL)
+	return &http.Client{Transport: &rewriteTransport{target: target, base: s.srv.Client().Transport}}
+}
+
+// Client returns a DriveClient wired to the fake.
+func (s *Server) Client(ctx context.Context) (*gdrive.DriveClient, error) {
+	return gdrive.NewDriveClient(ctx, s.HTTPClient())
+}
+
+// rewriteTransport redirects requests to the test server.
+type rewriteTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	r.Host = ""
+	return t.base.RoundTrip(r)
+}
+
+// InjectFault adds a fault. Faults are checked in the order they were added,
+// and only the first matching fault applies to a request.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the requests served so far as "METHOD /path" strings.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// ResetRequests clears the request log.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// SetStorageLimit sets the storage quota in bytes. Zero means unlimited.
+func (s *Server) SetStorageLimit(limit int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.storageLimit = limit
+}
+
+// AddFolder creates a folder and returns its ID.
+// An empty parentID places it in the root.
+func (s *Server) AddFolder(name, parentID string) string {
+	return s.add(&drive.File{Name: name, MimeType: folderMimeType, Parents: parentList(parentID)}, nil)
+}
+
+// AddFile creates a binary file and returns its ID.
+// An empty parentID places it in the root.
+func (s *Server) AddFile(name, parentID, mimeType string, content []byte) string {
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return s.add(&drive.File{Name: name, MimeType: mimeType, Parents: parentList(parentID)}, content)
+}
+
+// AddWorkspaceDocument creates a Google Workspace document and returns its ID.
+// exports holds the content served for each export format; formats without an
+// entry export an empty body.
+func (s *Server) AddWorkspaceDocument(name, parentID string, t gdrive.WorkspaceType, exports map[gdrive.ExportFormat][]byte) string {
+	id := s.add(&drive.File{Name: name, MimeType: string(t), Parents: parentList(parentID)}, nil)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.files[id]
+	for format, content := range exports {
+		f.exports[string(format)] = content
+	}
+	return id
+}
+
+// AddShortcut creates a shortcut to targetID and returns its ID.
+func (s *Server) AddShortcut(name, parentID, targetID string) string {
+	return s.add(&drive.File{
+		Name:            name,
+		MimeType:        shortcutMimeType,
+		Parents:         parentList(parentID),
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: targetID},
+	}, nil)
+}
+
+// add creates a file from test setup code and panics on invalid input.
+func (s *Server) add(meta *drive.File, content []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, apiErr := s.createFile(meta, content, meta.MimeType, false)
+	if apiErr != nil {
+		panic("gdrivetest: " + apiErr.message)
+	}
+	return f.meta.Id
+}
+
+// Update modifies the stored metadata of a file, e.g. to set OwnedByMe or
+// Starred, and records a change. It reports whether the file exists.
+func (s *Server) Update(id string, fn func(f *drive.File)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[id]
+	if !ok {
+		return false
+	}
+	fn(f.meta)
+	s.touch(f)
+	return true
+}
+
+// File returns a copy of the metadata of a file as the API would return it.
+func (s *Server) File(id string) (*drive.File, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[id]
+	if !ok {
+		return nil, false
+	}
+	return s.view(f), true
+}
+
+// Content returns the current content of a binary file.
+func (s *Server) Content(id string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[id]
+	if !ok {
+		return nil, false
+	}
+	return f.content, true
+}
+
+// serveHTTP logs the request, applies faults and dispatches to the API routes.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	fault := s.matchFault(r)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Latency > 0 {
+			select {
+			case <-time.After(fault.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.Status != 0 {
+			writeError(w, &apiError{code: fault.Status, reason: faultReason(fault.Status), message: "Injected fault"})
+			return
+		}
+		if fault.TruncateAfter > 0 {
+			w = &truncatingWriter{ResponseWriter: w, remaining: fault.TruncateAfter}
+		}
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// matchFault returns the first fault matching r and consumes one of its uses.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if f.Path != "" && !strings.Contains(r.URL.Path, f.Path) {
+			continue
+		}
+		if f.Count > 0 {
+			f.Count--
+			if f.Count == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// faultReason returns the Drive error reason for an injected status code.
+func faultReason(status int) string {
+	switch status {
+	case http.StatusTooManyRequests:
+		return "rateLimitExceeded"
+	case http.StatusForbidden:
+		return "userRateLimitExceeded"
+	case http.StatusServiceUnavailable:
+		return "backendError"
+	default:
+		return "internalError"
+	}
+}
+
+// truncatingWriter aborts the response once remaining body bytes are written.
+type truncatingWriter struct {
+	http.ResponseWriter
+	remaining int64
+}
+
+func (t *truncatingWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) <= t.remaining {
+		t.remaining -= int64(len(p))
+		return t.ResponseWriter.Write(p)
+	}
+	t.ResponseWriter.Write(p[:t.remaining])
+	if f, ok := t.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+	// Aborting closes the connection without a proper end of body.
+	panic(http.ErrAbortHandler)
+}
+
+// apiError is a Drive API error response.
+type apiError struct {
+	code    int
+	reason  string
+	message string
+}
+
+func notFound(id string) *apiError {
+	return &apiError{code: http.StatusNotFound, reason: "notFound", message: "File not found: " + id + "."}
+}
+
+func badRequest(message string) *apiError {
+	return &apiError{code: http.StatusBadRequest, reason: "badRequest", message: message}
+}
+
+// writeError writes e in the JSON format of Google API errors.
+func writeError(w http.ResponseWriter, e *apiError) {
+	writeJSON(w, e.code, map[string]any{
+		"error": map[string]any{
+			"code":    e.code,
+			"message": e.message,
+			"errors": []map[string]string{{
+				"domain":  "global",
+				"reason":  e.reason,
+				"message": e.message,
+			}},
+		},
+	})
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// now returns the current time in Drive's timestamp format.
+func (s *Server) now() string {
+	return time.Now().UTC().Format(timeFormat)
+}
+
+// newID returns a new unique ID with the given prefix.
+func (s *Server) newID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s%06d", prefix, s.seq)
+}
+
+// recordChange appends an entry to the changes feed.
+func (s *Server) recordChange(fileID string, removed bool) {
+	s.changes = append(s.changes, change{fileID: fileID, removed: removed, time: s.now()})
+}
+
+// touch bumps the version and modification time of f and records a change.
+func (s *Server) touch(f *file) {
+	f.meta.Version++
+	f.meta.ModifiedTime = s.now()
+	s.recordChange(f.meta.Id, false)
+}
+
+// parentList returns the parents of a new item; empty means the root.
+func parentList(parentID string) []string {
+	if parentID == "" {
+		return nil
+	}
+	return []string{parentID}
+}
+
+// md5Hex returns the hex encoded MD5 checksum of b.
+func md5Hex(b []byte) string {
+	sum := md5.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// isWorkspace reports whether mimeType is a native Google type, including folders and shortcuts.
+func isWorkspace(mimeType string) bool {
+	return strings.HasPrefix(mimeType, workspacePrefix)
+}
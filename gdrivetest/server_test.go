@@ -0,0 +1,255 @@
+package gdrivetest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abiiranathan/gdrive/gdrivetest"
+	"google.golang.org/api/drive/v3"
+)
+
+const apiBase = "https://www.googleapis.com"
+
+// do sends a request to the fake and returns the response with its body read.
+func do(t *testing.T, srv *gdrivetest.Server, method, url, body string, header map[string]string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := srv.HTTPClient().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("%s %s: reading body: %v", method, url, err)
+	}
+	return resp, data
+}
+
+func TestRangeDownload(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	id := srv.AddFile("digits.txt", "", "text/plain", []byte("0123456789"))
+	url := apiBase + "/drive/v3/files/" + id + "?alt=media"
+
+	tests := []struct {
+		rangeHeader  string
+		status       int
+		body         string
+		contentRange string
+	}{
+		{"", http.StatusOK, "0123456789", ""},
+		{"bytes=2-5", http.StatusPartialContent, "2345", "bytes 2-5/10"},
+		{"bytes=7-", http.StatusPartialContent, "789", "bytes 7-9/10"},
+		{"bytes=-3", http.StatusPartialContent, "789", "bytes 7-9/10"},
+		{"bytes=8-20", http.StatusPartialContent, "89", "bytes 8-9/10"},
+		{"bytes=20-", http.StatusRequestedRangeNotSatisfiable, "", "bytes */10"},
+	}
+	for _, tt := range tests {
+		header := map[string]string{}
+		if tt.rangeHeader != "" {
+			header["Range"] = tt.rangeHeader
+		}
+		resp, body := do(t, srv, http.MethodGet, url, "", header)
+		if resp.StatusCode != tt.status {
+			t.Errorf("Range %q: status %d, want %d", tt.rangeHeader, resp.StatusCode, tt.status)
+			continue
+		}
+		if got := resp.Header.Get("Content-Range"); got != tt.contentRange {
+			t.Errorf("Range %q: Content-Range %q, want %q", tt.rangeHeader, got, tt.contentRange)
+		}
+		if tt.status != http.StatusRequestedRangeNotSatisfiable && string(body) != tt.body {
+			t.Errorf("Range %q: body %q, want %q", tt.rangeHeader, body, tt.body)
+		}
+	}
+}
+
+func TestResumableUpload(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	resp, _ := do(t, srv, http.MethodPost, apiBase+"/upload/drive/v3/files?uploadType=resumable",
+		`{"name":"big.bin"}`, map[string]string{
+			"Content-Type":          "application/json",
+			"X-Upload-Content-Type": "application/octet-stream",
+		})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("starting session: status %d", resp.StatusCode)
+	}
+	session := resp.Header.Get("Location")
+	if session == "" {
+		t.Fatal("no session URL in Location")
+	}
+
+	// The total size is unknown until the last chunk.
+	resp, _ = do(t, srv, http.MethodPut, session, "abcd", map[string]string{"Content-Range": "bytes 0-3/*"})
+	if resp.StatusCode != http.StatusPermanentRedirect {
+		t.Fatalf("first chunk: status %d, want 308", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Range"); got != "bytes=0-3" {
+		t.Errorf("first chunk: Range %q, want bytes=0-3", got)
+	}
+
+	// A status query reports the received bytes without changing them.
+	resp, _ = do(t, srv, http.MethodPut, session, "", map[string]string{"Content-Range": "bytes */10"})
+	if resp.StatusCode != http.StatusPermanentRedirect || resp.Header.Get("Range") != "bytes=0-3" {
+		t.Errorf("status query: status %d, Range %q", resp.StatusCode, resp.Header.Get("Range"))
+	}
+
+	// A chunk that does not continue the upload is rejected.
+	resp, _ = do(t, srv, http.MethodPut, session, "efgh", map[string]string{"Content-Range": "bytes 2-5/10"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("misaligned chunk: status %d, want 400", resp.StatusCode)
+	}
+	resp, _ = do(t, srv, http.MethodPut, session, "efgh", map[string]string{"Content-Range": "4-7/10"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("malformed Content-Range: status %d, want 400", resp.StatusCode)
+	}
+
+	// Clients that cannot handle 308 get 200 with an override header.
+	resp, _ = do(t, srv, http.MethodPut, session, "efgh", map[string]string{
+		"Content-Range":      "bytes 4-7/*",
+		"X-GUploader-No-308": "yes",
+	})
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Http-Status-Code-Override") != "308" {
+		t.Errorf("no-308 chunk: status %d, override %q", resp.StatusCode, resp.Header.Get("X-Http-Status-Code-Override"))
+	}
+
+	resp, body := do(t, srv, http.MethodPut, session, "ij", map[string]string{"Content-Range": "bytes 8-9/10"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("last chunk: status %d: %s", resp.StatusCode, body)
+	}
+	var created drive.File
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Name != "big.bin" || created.MimeType != "application/octet-stream" {
+		t.Errorf("created %q (%s), want big.bin (application/octet-stream)", created.Name, created.MimeType)
+	}
+	if content, _ := srv.Content(created.Id); string(content) != "abcdefghij" {
+		t.Errorf("content %q, want abcdefghij", content)
+	}
+
+	// The session is gone once the upload completes.
+	resp, _ = do(t, srv, http.MethodPut, session, "", map[string]string{"Content-Range": "bytes */10"})
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("completed session: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestResumableUpdateOfMissingFile(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	resp, _ := do(t, srv, http.MethodPatch, apiBase+"/upload/drive/v3/files/missing?uploadType=resumable", "{}", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestInjectFaultStatusAndCount(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	id := srv.AddFile("a.txt", "", "text/plain", []byte("a"))
+	url := apiBase + "/drive/v3/files/" + id
+
+	srv.InjectFault(gdrivetest.Fault{Path: "/files/" + id, Status: http.StatusServiceUnavailable, Count: 2})
+	for i := range 2 {
+		resp, body := do(t, srv, http.MethodGet, url, "", nil)
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("request %d: status %d, want 503", i, resp.StatusCode)
+		}
+		if !strings.Contains(string(body), "backendError") {
+			t.Errorf("request %d: body %s lacks the backendError reason", i, body)
+		}
+	}
+	if resp, _ := do(t, srv, http.MethodGet, url, "", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("after the fault: status %d, want 200", resp.StatusCode)
+	}
+	if got := len(srv.Requests()); got != 3 {
+		t.Errorf("%d requests logged, want 3", got)
+	}
+}
+
+func TestInjectFaultMatching(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	id := srv.AddFile("a.txt", "", "text/plain", []byte("a"))
+	other := srv.AddFile("b.txt", "", "text/plain", []byte("b"))
+
+	srv.InjectFault(gdrivetest.Fault{Method: http.MethodDelete, Path: "/files/" + id, Status: http.StatusTooManyRequests})
+
+	if resp, _ := do(t, srv, http.MethodGet, apiBase+"/drive/v3/files/"+id, "", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("GET with a DELETE fault: status %d, want 200", resp.StatusCode)
+	}
+	if resp, _ := do(t, srv, http.MethodDelete, apiBase+"/drive/v3/files/"+other, "", nil); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE of another file: status %d, want 204", resp.StatusCode)
+	}
+	for range 2 {
+		resp, body := do(t, srv, http.MethodDelete, apiBase+"/drive/v3/files/"+id, "", nil)
+		if resp.StatusCode != http.StatusTooManyRequests || !strings.Contains(string(body), "rateLimitExceeded") {
+			t.Errorf("DELETE with the fault: status %d, body %s", resp.StatusCode, body)
+		}
+	}
+
+	srv.ClearFaults()
+	if resp, _ := do(t, srv, http.MethodDelete, apiBase+"/drive/v3/files/"+id, "", nil); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE after ClearFaults: status %d, want 204", resp.StatusCode)
+	}
+}
+
+func TestInjectFaultTruncate(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	id := srv.AddFile("digits.txt", "", "text/plain", []byte("0123456789"))
+
+	srv.InjectFault(gdrivetest.Fault{Path: "/files/" + id, TruncateAfter: 4, Count: 1})
+
+	resp, err := srv.HTTPClient().Get(apiBase + "/drive/v3/files/" + id + "?alt=media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("reading a truncated body: %v, want unexpected EOF", err)
+	}
+	if string(data) != "0123" {
+		t.Errorf("truncated body %q, want 0123", data)
+	}
+}
+
+func TestInjectFaultLatency(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	url := apiBase + "/drive/v3/about?fields=user"
+
+	srv.InjectFault(gdrivetest.Fault{Path: "/about", Latency: 50 * time.Millisecond, Count: 1})
+	start := time.Now()
+	if resp, _ := do(t, srv, http.MethodGet, url, "", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want 200", resp.StatusCode)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("request took %v, want at least 50ms", elapsed)
+	}
+
+	srv.InjectFault(gdrivetest.Fault{Path: "/about", Latency: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if _, err := srv.HTTPClient().Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("request with a short deadline: %v, want deadline exceeded", err)
+	}
+}
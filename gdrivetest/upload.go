@@ -0,0 +1,220 @@
+package gdrivetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// uploadSession is an open resumable upload.
+type uploadSession struct {
+	fileID    string // Empty for creates
+	meta      []byte // JSON metadata of the initial request
+	mediaType string
+	query     url.Values
+	content   []byte
+}
+
+// handleUpload serves files.create and files.update on the upload endpoint
+// for the multipart, media and resumable upload types.
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	fileID := r.PathValue("id")
+	query := r.URL.Query()
+
+	switch query.Get("uploadType") {
+	case "multipart":
+		meta, content, mediaType, err := readMultipart(r)
+		if err != nil {
+			writeError(w, badRequest("Invalid multipart upload: "+err.Error()))
+			return
+		}
+		s.finishUpload(w, fileID, meta, true, content, mediaType, query)
+
+	case "media":
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, badRequest("Unable to read request body."))
+			return
+		}
+		s.finishUpload(w, fileID, nil, true, content, r.Header.Get("Content-Type"), query)
+
+	case "resumable":
+		meta, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, badRequest("Unable to read request body."))
+			return
+		}
+
+		s.mu.Lock()
+		if fileID != "" {
+			if _, ok := s.files[fileID]; !ok {
+				s.mu.Unlock()
+				writeError(w, notFound(fileID))
+				return
+			}
+		}
+		sessionID := s.newID("u")
+		s.uploads[sessionID] = &uploadSession{
+			fileID:    fileID,
+			meta:      meta,
+			mediaType: r.Header.Get("X-Upload-Content-Type"),
+			query:     query,
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("Location", apiBase+"/upload/drive/v3/sessions/"+sessionID)
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		writeError(w, badRequest("Unsupported uploadType: "+query.Get("uploadType")))
+	}
+}
+
+// handleUploadChunk receives a chunk of a resumable upload. Incomplete
+// uploads are answered with 308 Resume Incomplete, or with 200 and an
+// X-Http-Status-Code-Override header when the client asks for it.
+func (s *Server) handleUploadChunk(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("session")
+	chunk, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, badRequest("Unable to read request body."))
+		return
+	}
+
+	first, total, err := parseContentRange(r.Header.Get("Content-Range"), len(chunk))
+	if err != nil {
+		writeError(w, badRequest(err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	session, ok := s.uploads[sessionID]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, notFound(sessionID))
+		return
+	}
+	if first >= 0 {
+		if first != int64(len(session.content)) {
+			s.mu.Unlock()
+			writeError(w, badRequest(fmt.Sprintf("Chunk starts at %d, expected %d.", first, len(session.content))))
+			return
+		}
+		session.content = append(session.content, chunk...)
+	}
+	received := int64(len(session.content))
+	complete := total >= 0 && received >= total
+	if complete {
+		delete(s.uploads, sessionID)
+	}
+	s.mu.Unlock()
+
+	if complete {
+		s.finishUpload(w, session.fileID, session.meta, true, session.content, session.mediaType, session.query)
+		return
+	}
+
+	if received > 0 {
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", received-1))
+	}
+	if r.Header.Get("X-GUploader-No-308") == "yes" {
+		w.Header().Set("X-Http-Status-Code-Override", "308")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusPermanentRedirect)
+}
+
+// parseContentRange parses "bytes a-b/total", "bytes a-b/*" and "bytes */total".
+// first is -1 for status queries, and total is -1 while the size is unknown.
+func parseContentRange(header string, length int) (first, total int64, err error) {
+	if header == "" {
+		return 0, int64(length), nil
+	}
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %s", header)
+	}
+	rangePart, totalPart, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %s", header)
+	}
+
+	total = -1
+	if totalPart != "*" {
+		if total, err = strconv.ParseInt(totalPart, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid Content-Range: %s", header)
+		}
+	}
+	if rangePart == "*" {
+		return -1, total, nil
+	}
+	start, _, ok := strings.Cut(rangePart, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %s", header)
+	}
+	if first, err = strconv.ParseInt(start, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %s", header)
+	}
+	return first, total, nil
+}
+
+// readMultipart splits a multipart/related upload into metadata and media.
+func readMultipart(r *http.Request) (meta, content []byte, mediaType string, err error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, "", err
+	}
+	mr := multipart.NewReader(r.Body, params["boundary"])
+
+	part, err := mr.NextPart()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("missing metadata part: %w", err)
+	}
+	if meta, err = io.ReadAll(part); err != nil {
+		return nil, nil, "", err
+	}
+
+	part, err = mr.NextPart()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("missing media part: %w", err)
+	}
+	if content, err = io.ReadAll(part); err != nil {
+		return nil, nil, "", err
+	}
+	return meta, content, part.Header.Get("Content-Type"), nil
+}
+
+// finishUpload creates (empty fileID) or updates a file and writes its metadata.
+func (s *Server) finishUpload(w http.ResponseWriter, fileID string, meta []byte, hasMedia bool, content []byte, mediaType string, query url.Values) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var f *file
+	var apiErr *apiError
+	if fileID == "" {
+		created := &drive.File{}
+		if len(meta) > 0 {
+			if err := json.Unmarshal(meta, created); err != nil {
+				writeError(w, badRequest("Invalid JSON payload: "+err.Error()))
+				return
+			}
+		}
+		f, apiErr = s.createFile(created, content, mediaType, query.Get("keepRevisionForever") == "true")
+	} else {
+		f, apiErr = s.updateFile(fileID, meta, hasMedia, content, mediaType, query)
+	}
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.view(f))
+}
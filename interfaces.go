@@ -0,0 +1,124 @@
+package gdrive
+
+import (
+	"context"
+	"io"
+)
+
+// Lister lists files and reads file metadata.
+type Lister interface {
+	ListFiles(ctx context.Context) ([]FileInfo, error)
+	ListFilesInFolder(ctx context.Context, parentFolderID string) ([]FileInfo, error)
+	ListFilesInFolderWithOptions(ctx context.Context, parentFolderID string, opts ListOptions) ([]FileInfo, error)
+	GetFile(ctx context.Context, fileID string) (*FileInfo, error)
+}
+
+// Downloader downloads the content of binary files.
+type Downloader interface {
+	StreamFile(ctx context.Context, fileID string, w io.Writer) (int64, error)
+	DownloadFile(ctx context.Context, fileID, outputPath string) (int64, error)
+	PartialStreamFile(ctx context.Context, fileID string, w io.Writer, startByte, endByte int64) (int64, error)
+}
+
+// Uploader creates files and replaces their content.
+type Uploader interface {
+	UploadFile(ctx context.Context, filePath, fileName, parentFolderID string) (string, error)
+	UploadFileFromReader(ctx context.Context, reader io.Reader, fileName, mimeType, parentFolderID string) (string, error)
+	UploadFileFromReaderWithInfo(ctx context.Context, reader io.Reader, fileName, mimeType, parentFolderID string) (*FileInfo, error)
+	UploadFileWithOptions(ctx context.Context, filePath string, opts UploadOptions) (*FileInfo, error)
+	UpdateFileContent(ctx context.Context, fileID string, reader io.Reader, opts UpdateContentOptions) (*FileInfo, error)
+	CreateFolder(ctx context.Context, folderName, parentFolderID string) (string, error)
+}
+
+// Exporter exports Google Workspace documents.
+type Exporter interface {
+	IsWorkspaceDocument(ctx context.Context, fileID string) (bool, error)
+	ExportWorkspaceDocument(ctx context.Context, fileID string, w io.Writer, format ExportFormat) (int64, error)
+	ExportWorkspaceDocumentWithOptions(ctx context.Context, fileID string, w io.Writer, format ExportFormat, opts ExportOptions) (int64, error)
+	GetExportLinks(ctx context.Context, fileID string) (map[string]string, error)
+}
+
+// Trasher moves files to and from the trash.
+type Trasher interface {
+	TrashFile(ctx context.Context, fileID string) error
+	RestoreFile(ctx context.Context, fileID string) error
+	ListTrash(ctx context.Context) ([]TrashedFile, error)
+	EmptyTrash(ctx context.Context, opts EmptyTrashOptions) error
+}
+
+// RevisionReader reads the revision history of files.
+type RevisionReader interface {
+	ListRevisions(ctx context.Context, fileID string) ([]Revision, error)
+	GetRevision(ctx context.Context, fileID, revisionID string) (*Revision, error)
+	DownloadRevision(ctx context.Context, fileID, revisionID string, w io.Writer) (int64, error)
+}
+
+// Thumbnailer fetches thumbnail images of files.
+type Thumbnailer interface {
+	GetThumbnail(ctx context.Context, fileID string, size int, w io.Writer) (int64, string, error)
+	GetFileThumbnail(ctx context.Context, file *FileInfo, size int, w io.Writer) (int64, string, error)
+}
+
+// Client combines the narrow interfaces Lister, Downloader, Uploader, Exporter,
+// Trasher, RevisionReader and Thumbnailer. DriveClient implements all of them. Prefer
+// depending on the smallest interface that covers what a component needs, so
+// that tests can pass a fake or a decorated client.
+//
+// Decorators such as logging, metrics, retries or caching wrap an existing
+// implementation and override the methods they care about; embedding the
+// wrapped interface forwards everything else.
+//
+// Example:
+//
+//	// loggingDownloader logs every StreamFile call.
+//	type loggingDownloader struct {
+//	    gdrive.Downloader
+//	}
+//
+//	func (l loggingDownloader) StreamFile(ctx context.Context, fileID string, w io.Writer) (int64, error) {
+//	    start := time.Now()
+//	    n, err := l.Downloader.StreamFile(ctx, fileID, w)
+//	    log.Printf("stream %s: %d bytes in %s, err=%v", fileID, n, time.Since(start), err)
+//	    return n, err
+//	}
+//
+//	var d gdrive.Downloader = loggingDownloader{client}
+type Client interface {
+	Lister
+	Downloader
+	Uploader
+	Exporter
+	Trasher
+	RevisionReader
+	Thumbnailer
+}
+
+// FileServerClient is what FileServer needs: metadata, downloads and exports.
+type FileServerClient interface {
+	Lister
+	Downloader
+	Exporter
+}
+
+// UploadHandlerClient is what UploadHandler needs: uploads.
+type UploadHandlerClient interface {
+	Uploader
+}
+
+// ThumbnailHandlerClient is what ThumbnailHandler needs: metadata and thumbnails.
+type ThumbnailHandlerClient interface {
+	Lister
+	Thumbnailer
+}
+
+// DriveClient implements every interface.
+var (
+	_ Lister         = (*DriveClient)(nil)
+	_ Downloader     = (*DriveClient)(nil)
+	_ Uploader       = (*DriveClient)(nil)
+	_ Exporter       = (*DriveClient)(nil)
+	_ Trasher        = (*DriveClient)(nil)
+	_ RevisionReader = (*DriveClient)(nil)
+	_ Thumbnailer    = (*DriveClient)(nil)
+	_ Client         = (*DriveClient)(nil)
+)
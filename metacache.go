@@ -0,0 +1,591 @@
+package gdrive
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// metadataCacheFields is the partial response selector for cached file metadata.
+// It covers every field read through the cache.
+const metadataCacheFields = fileInfoFields + ", exportLinks, thumbnailLink"
+
+// MetadataCacheOptions configures EnableMetadataCache.
+type MetadataCacheOptions struct {
+	// TTL is how long an entry is served before it is fetched again,
+	// even without a change notification. Defaults to 5 minutes.
+	TTL time.Duration
+
+	// MaxFiles bounds the number of cached file metadata entries.
+	// The least recently used entries are evicted first. Defaults to 10000.
+	MaxFiles int
+
+	// MaxFolders bounds the number of cached folder listings. Defaults to 1000.
+	MaxFolders int
+
+	// PollInterval is how often the changes feed is polled for invalidations.
+	// Defaults to 30 seconds. A negative value disables polling; call Sync instead.
+	PollInterval time.Duration
+
+	// ErrorLog receives errors from background polling, which has no caller
+	// to return them to. If nil, these errors are not logged.
+	ErrorLog *log.Logger
+}
+
+// CacheStats reports the activity of a MetadataCache.
+type CacheStats struct {
+	Hits          int64 // Lookups served from the cache
+	Misses        int64 // Lookups that called the API
+	Invalidations int64 // Entries dropped because of changes
+	Files         int   // File metadata entries currently cached
+	Folders       int   // Folder listings currently cached
+}
+
+// MetadataCache caches file metadata, the folder tree and folder listings of a
+// DriveClient. Entries expire after a TTL and are invalidated by polling the
+// Drive changes feed, so changes made elsewhere are picked up within the poll
+// interval. Changes made through the same DriveClient invalidate the affected
+// entries immediately.
+//
+// While enabled, GetFile, IsWorkspaceDocument, GetExportLinks and
+// ListFilesInFolder are served from the cache when possible. ListFiles and
+// ListFilesInFolder use the cached folder tree to build folder paths, but
+// ListFiles always lists the files themselves from Drive.
+// Safe for concurrent use by multiple goroutines.
+type MetadataCache struct {
+	dc   *DriveClient
+	opts MetadataCacheOptions
+
+	mu         sync.Mutex
+	files      *lruCache[*drive.File]   // file metadata by ID
+	children   *lruCache[[]*drive.File] // folder listings by parent ID; "" for all files
+	memberOf   map[string][]string      // file ID to the listings containing it
+	folders    []*drive.File            // folder tree
+	folderIDs  map[string]bool          // IDs in folders
+	foldersExp time.Time                // expiry of folders
+	pageToken  string                   // changes feed position
+	generation uint64                   // bumped by every invalidation
+	stats      CacheStats
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// EnableMetadataCache puts a metadata cache in front of the client's metadata
+// lookups and starts polling the changes feed in the background. Polling stops
+// when ctx is canceled or Close is called. Enabling a cache replaces any
+// previous one.
+//
+// Parameters:
+//   - ctx: Context controlling the lifetime of the background polling
+//   - opts: TTL, size bounds, poll interval and error log; zero values use the defaults
+//
+// Returns:
+//   - *MetadataCache: The enabled cache
+//   - error: Any error encountered while fetching the changes feed start token
+//
+// Example:
+//
+//	cache, err := client.EnableMetadataCache(ctx, gdrive.MetadataCacheOptions{TTL: 10 * time.Minute})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer cache.Close()
+func (dc *DriveClient) EnableMetadataCache(ctx context.Context, opts MetadataCacheOptions) (*MetadataCache, error) {
+	if opts.TTL <= 0 {
+		opts.TTL = 5 * time.Minute
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = 10000
+	}
+	if opts.MaxFolders <= 0 {
+		opts.MaxFolders = 1000
+	}
+	if opts.PollInterval == 0 {
+		opts.PollInterval = 30 * time.Second
+	}
+
+	start, err := dc.service.Changes.GetStartPageToken().Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get changes start token: %w", err)
+	}
+
+	pollCtx, cancel := context.WithCancel(ctx)
+	c := &MetadataCache{
+		dc:        dc,
+		opts:      opts,
+		files:     newLRUCache[*drive.File](opts.MaxFiles),
+		children:  newLRUCache[[]*drive.File](opts.MaxFolders),
+		memberOf:  make(map[string][]string),
+		pageToken: start.StartPageToken,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	c.children.onDrop = c.unlinkListing
+
+	if previous := dc.metaCache.Swap(c); previous != nil {
+		previous.stop()
+	}
+
+	if opts.PollInterval > 0 {
+		go c.poll(pollCtx)
+	} else {
+		close(c.done)
+	}
+	return c, nil
+}
+
+// Close stops polling and detaches the cache from its client.
+func (c *MetadataCache) Close() {
+	c.dc.metaCache.CompareAndSwap(c, nil)
+	c.stop()
+}
+
+// stop ends background polling and waits for it to finish.
+func (c *MetadataCache) stop() {
+	c.cancel()
+	<-c.done
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *MetadataCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Files = c.files.len()
+	stats.Folders = c.children.len()
+	return stats
+}
+
+// Purge drops every entry.
+func (c *MetadataCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.purgeLocked()
+}
+
+func (c *MetadataCache) purgeLocked() {
+	c.generation++
+	c.files.clear()
+	c.children.clear()
+	clear(c.memberOf)
+	c.folders, c.folderIDs = nil, nil
+}
+
+// Invalidate drops the entries of a file: its metadata, the listings that
+// contain it and, if it is a folder, the folder tree.
+func (c *MetadataCache) Invalidate(fileID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.invalidateLocked(fileID, nil, false)
+}
+
+// invalidateLocked drops the entries affected by a change to fileID.
+// parents are the current parents of the file, if known.
+//
+// It also bumps the generation, so that fetches which started before the
+// change do not store what may already be stale.
+func (c *MetadataCache) invalidateLocked(fileID string, parents []string, isFolder bool) {
+	c.generation++
+	if c.files.remove(fileID) {
+		c.stats.Invalidations++
+	}
+
+	// Removing a listing unlinks its members from memberOf, so iterate over a copy.
+	listings := append(slices.Clone(c.memberOf[fileID]), parents...)
+	listings = append(listings, "")
+	for _, key := range listings {
+		if c.children.remove(key) {
+			c.stats.Invalidations++
+		}
+	}
+	delete(c.memberOf, fileID)
+
+	if isFolder || c.folderIDs[fileID] {
+		if c.folders != nil {
+			c.stats.Invalidations++
+		}
+		c.folders, c.folderIDs = nil, nil
+	}
+}
+
+// Sync applies the changes recorded since the last sync. It is called
+// periodically when polling is enabled.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//
+// Returns:
+//   - error: Any error encountered while reading the changes feed
+//
+// Example:
+//
+//	// After an external process modified files
+//	if err := cache.Sync(ctx); err != nil {
+//	    log.Printf("cache sync failed: %v", err)
+//	}
+func (c *MetadataCache) Sync(ctx context.Context) error {
+	c.mu.Lock()
+	pageToken := c.pageToken
+	c.mu.Unlock()
+
+	for {
+		r, err := c.dc.service.Changes.List(pageToken).
+			Context(ctx).
+			PageSize(1000).
+			Fields("nextPageToken, newStartPageToken, changes(fileId, removed, file(mimeType, parents))").
+			Do()
+		if err != nil {
+			var apiErr *googleapi.Error
+			if errors.As(err, &apiErr) && (apiErr.Code == http.StatusBadRequest || apiErr.Code == http.StatusNotFound) {
+				// The token is no longer valid; start over from an empty cache.
+				return c.reset(ctx)
+			}
+			return fmt.Errorf("unable to list changes: %w", err)
+		}
+
+		c.mu.Lock()
+		for _, change := range r.Changes {
+			var parents []string
+			isFolder := false
+			if change.File != nil {
+				parents = change.File.Parents
+				isFolder = change.File.MimeType == folderMimeType
+			}
+			c.invalidateLocked(change.FileId, parents, isFolder)
+		}
+		if r.NewStartPageToken != "" {
+			c.pageToken = r.NewStartPageToken
+		} else {
+			c.pageToken = r.NextPageToken
+		}
+		pageToken = c.pageToken
+		c.mu.Unlock()
+
+		if r.NextPageToken == "" {
+			return nil
+		}
+	}
+}
+
+// reset purges the cache and restarts the changes feed from now.
+func (c *MetadataCache) reset(ctx context.Context) error {
+	start, err := c.dc.service.Changes.GetStartPageToken().Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to get changes start token: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.purgeLocked()
+	c.pageToken = start.StartPageToken
+	return nil
+}
+
+// poll syncs the cache until ctx is canceled.
+func (c *MetadataCache) poll(ctx context.Context) {
+	defer close(c.done)
+	ticker := time.NewTicker(c.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Sync(ctx); err != nil && !errors.Is(err, context.Canceled) && c.opts.ErrorLog != nil {
+				c.opts.ErrorLog.Printf("gdrive: unable to sync metadata cache: %v", err)
+			}
+		}
+	}
+}
+
+// file returns the metadata of a file, from the cache if possible.
+func (c *MetadataCache) file(ctx context.Context, fileID string) (*drive.File, error) {
+	c.mu.Lock()
+	if f, ok := c.files.get(fileID, time.Now()); ok {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return f, nil
+	}
+	c.stats.Misses++
+	generation := c.generation
+	c.mu.Unlock()
+
+	f, err := c.dc.service.Files.Get(fileID).
+		Context(ctx).
+		Fields(metadataCacheFields).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.files.put(fileID, f, time.Now().Add(c.opts.TTL))
+	}
+	c.mu.Unlock()
+	return f, nil
+}
+
+// folderTree returns the cached folder tree, or nil and false on a miss.
+// On a miss it also returns the generation to pass to storeFolderTree.
+func (c *MetadataCache) folderTree() ([]*drive.File, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.folders == nil || time.Now().After(c.foldersExp) {
+		c.stats.Misses++
+		return nil, c.generation, false
+	}
+	c.stats.Hits++
+	return c.folders, c.generation, true
+}
+
+// storeFolderTree caches the folder tree fetched after a miss in generation.
+// It is dropped if the cache was invalidated in the meantime.
+func (c *MetadataCache) storeFolderTree(folders []*drive.File, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation {
+		return
+	}
+	c.folders = folders
+	c.foldersExp = time.Now().Add(c.opts.TTL)
+	c.folderIDs = make(map[string]bool, len(folders))
+	for _, f := range folders {
+		c.folderIDs[f.Id] = true
+	}
+}
+
+// listing returns the cached items of a folder listing, or nil and false on a miss.
+// On a miss it also returns the generation to pass to storeListing.
+func (c *MetadataCache) listing(parentID string) ([]*drive.File, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	items, ok := c.children.get(parentID, time.Now())
+	if ok {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+	return items, c.generation, ok
+}
+
+// storeListing caches the items of a folder listing fetched after a miss in
+// generation. It is dropped if the cache was invalidated in the meantime.
+func (c *MetadataCache) storeListing(parentID string, items []*drive.File, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation {
+		return
+	}
+	c.children.put(parentID, items, time.Now().Add(c.opts.TTL))
+	for _, item := range items {
+		if !slices.Contains(c.memberOf[item.Id], parentID) {
+			c.memberOf[item.Id] = append(c.memberOf[item.Id], parentID)
+		}
+	}
+}
+
+// unlinkListing removes the listing of parentID from the memberOf entries of
+// its items once the listing leaves the cache, so memberOf stays bounded by
+// the cached listings.
+func (c *MetadataCache) unlinkListing(parentID string, items []*drive.File) {
+	for _, item := range items {
+		listings := slices.DeleteFunc(c.memberOf[item.Id], func(key string) bool { return key == parentID })
+		if len(listings) == 0 {
+			delete(c.memberOf, item.Id)
+		} else {
+			c.memberOf[item.Id] = listings
+		}
+	}
+}
+
+// metadataCache returns the enabled cache, or nil.
+func (dc *DriveClient) metadataCache() *MetadataCache {
+	return dc.metaCache.Load()
+}
+
+// getFileMetadata fetches file metadata with the given fields,
+// or all cached fields through the metadata cache if it is enabled.
+func (dc *DriveClient) getFileMetadata(ctx context.Context, fileID, fields string) (*drive.File, error) {
+	if c := dc.metadataCache(); c != nil {
+		return c.file(ctx, fileID)
+	}
+	return dc.service.Files.Get(fileID).
+		Context(ctx).
+		Fields(googleapi.Field(fields)).
+		Do()
+}
+
+// listFolders returns the folders used to resolve folder paths,
+// from the metadata cache if it is enabled.
+func (dc *DriveClient) listFolders(ctx context.Context) ([]*drive.File, error) {
+	c := dc.metadataCache()
+	var generation uint64
+	if c != nil {
+		folders, gen, ok := c.folderTree()
+		if ok {
+			return folders, nil
+		}
+		generation = gen
+	}
+
+	r, err := dc.service.Files.List().
+		Context(ctx).
+		Q("mimeType='application/vnd.google-apps.folder'").
+		Fields("files(id, name, parents)").
+		PageSize(1000).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+
+	if c != nil {
+		c.storeFolderTree(r.Files, generation)
+	}
+	return r.Files, nil
+}
+
+// listChildren returns every item matching query, the listing of parentID,
+// from the metadata cache if it is enabled.
+func (dc *DriveClient) listChildren(ctx context.Context, parentID, query string) ([]*drive.File, error) {
+	c := dc.metadataCache()
+	var generation uint64
+	if c != nil {
+		items, gen, ok := c.listing(parentID)
+		if ok {
+			return items, nil
+		}
+		generation = gen
+	}
+
+	var items []*drive.File
+	pageToken := ""
+	for {
+		call := dc.service.Files.List().
+			Context(ctx).
+			Q(query).
+			PageSize(MaxPageSize).
+			Fields("nextPageToken, files(" + fileInfoFields + ")")
+
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		r, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, r.Files...)
+
+		pageToken = r.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	if c != nil {
+		c.storeListing(parentID, items, generation)
+	}
+	return items, nil
+}
+
+// invalidateMetadata drops cached entries after the client changed a file.
+func (dc *DriveClient) invalidateMetadata(fileID string, parents []string, isFolder bool) {
+	if c := dc.metadataCache(); c != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.invalidateLocked(fileID, parents, isFolder)
+	}
+}
+
+// lruCache is a size-bounded least recently used cache with per-entry expiry.
+// It is not safe for concurrent use.
+type lruCache[V any] struct {
+	max   int
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+
+	// onDrop, if set, is called with each entry that is evicted, expires,
+	// is replaced or is removed. clear does not call it.
+	onDrop func(key string, value V)
+}
+
+type lruEntry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+func newLRUCache[V any](max int) *lruCache[V] {
+	return &lruCache[V]{max: max, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// get returns the value of key unless it is missing or expired at now.
+func (l *lruCache[V]) get(key string, now time.Time) (V, bool) {
+	var zero V
+	e, ok := l.items[key]
+	if !ok {
+		return zero, false
+	}
+	entry := e.Value.(*lruEntry[V])
+	if now.After(entry.expires) {
+		l.drop(e)
+		return zero, false
+	}
+	l.order.MoveToFront(e)
+	return entry.value, true
+}
+
+// put stores value under key, evicting the least recently used entry if full.
+func (l *lruCache[V]) put(key string, value V, expires time.Time) {
+	if e, ok := l.items[key]; ok {
+		if l.onDrop != nil {
+			l.onDrop(key, e.Value.(*lruEntry[V]).value)
+		}
+		e.Value = &lruEntry[V]{key: key, value: value, expires: expires}
+		l.order.MoveToFront(e)
+		return
+	}
+	l.items[key] = l.order.PushFront(&lruEntry[V]{key: key, value: value, expires: expires})
+	for l.order.Len() > l.max {
+		l.drop(l.order.Back())
+	}
+}
+
+// remove drops key and reports whether it was present.
+func (l *lruCache[V]) remove(key string) bool {
+	e, ok := l.items[key]
+	if ok {
+		l.drop(e)
+	}
+	return ok
+}
+
+// drop removes e and reports it to onDrop.
+func (l *lruCache[V]) drop(e *list.Element) {
+	entry := e.Value.(*lruEntry[V])
+	l.order.Remove(e)
+	delete(l.items, entry.key)
+	if l.onDrop != nil {
+		l.onDrop(entry.key, entry.value)
+	}
+}
+
+func (l *lruCache[V]) clear() {
+	l.order.Init()
+	clear(l.items)
+}
+
+func (l *lruCache[V]) len() int {
+	return l.order.Len()
+}
@@ -0,0 +1,120 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// ContentRestriction locks or unlocks the content of a file.
+type ContentRestriction struct {
+	// ReadOnly prevents changes to the content, name and description of the file
+	// until the restriction is lifted. False lifts an existing restriction.
+	ReadOnly bool
+
+	// Reason is shown to users who try to modify the file.
+	Reason string
+
+	// OwnerRestricted allows only the owner (or organizers in shared drives)
+	// to lift the restriction.
+	OwnerRestricted bool
+}
+
+// MetadataPatch specifies file metadata to change.
+// Nil fields are left unchanged; set fields are sent even if they hold the zero value.
+type MetadataPatch struct {
+	Name                         *string             // New display name
+	Description                  *string             // New description; empty clears it
+	Starred                      *bool               // Starred by the caller
+	FolderColorRgb               *string             // Folder color as "#RRGGBB"; Drive picks the closest palette color
+	WritersCanShare              *bool               // Whether editors can change sharing settings
+	CopyRequiresWriterPermission *bool               // Disable copy, print and download for readers and commenters
+	ViewersCanCopyContent        *bool               // Deprecated by Drive; use CopyRequiresWriterPermission
+	ContentRestriction           *ContentRestriction // Lock or unlock the content
+}
+
+// UpdateMetadata changes the metadata of a file or folder without touching its content.
+// Only the fields set in patch are sent.
+//
+// Note: While a file is read-only, the same request cannot change other
+// metadata; lift the restriction first, then update.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file or folder
+//   - patch: Fields to change; nil fields are left unchanged
+//
+// Returns:
+//   - *FileInfo: Metadata after the update (FolderPath is not populated)
+//   - error: Any error encountered during the API call
+//
+// Example:
+//
+//	// Lock a finalized contract
+//	info, err := client.UpdateMetadata(ctx, fileID, gdrive.MetadataPatch{
+//	    ContentRestriction: &gdrive.ContentRestriction{ReadOnly: true, Reason: "Signed 2024-06-01"},
+//	})
+//
+//	// Rename and star
+//	name, starred := "Contract (final).pdf", true
+//	info, err = client.UpdateMetadata(ctx, fileID, gdrive.MetadataPatch{Name: &name, Starred: &starred})
+func (dc *DriveClient) UpdateMetadata(ctx context.Context, fileID string, patch MetadataPatch) (*FileInfo, error) {
+	if fileID == "" {
+		return nil, errors.New("file ID cannot be empty")
+	}
+	if patch.Name != nil && *patch.Name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+
+	f := &drive.File{}
+	if patch.Name != nil {
+		f.Name = *patch.Name
+		f.ForceSendFields = append(f.ForceSendFields, "Name")
+	}
+	if patch.Description != nil {
+		f.Description = *patch.Description
+		f.ForceSendFields = append(f.ForceSendFields, "Description")
+	}
+	if patch.Starred != nil {
+		f.Starred = *patch.Starred
+		f.ForceSendFields = append(f.ForceSendFields, "Starred")
+	}
+	if patch.FolderColorRgb != nil {
+		f.FolderColorRgb = *patch.FolderColorRgb
+		f.ForceSendFields = append(f.ForceSendFields, "FolderColorRgb")
+	}
+	if patch.WritersCanShare != nil {
+		f.WritersCanShare = *patch.WritersCanShare
+		f.ForceSendFields = append(f.ForceSendFields, "WritersCanShare")
+	}
+	if patch.CopyRequiresWriterPermission != nil {
+		f.CopyRequiresWriterPermission = *patch.CopyRequiresWriterPermission
+		f.ForceSendFields = append(f.ForceSendFields, "CopyRequiresWriterPermission")
+	}
+	if patch.ViewersCanCopyContent != nil {
+		f.ViewersCanCopyContent = *patch.ViewersCanCopyContent
+		f.ForceSendFields = append(f.ForceSendFields, "ViewersCanCopyContent")
+	}
+	if r := patch.ContentRestriction; r != nil {
+		f.ContentRestrictions = []*drive.ContentRestriction{{
+			ReadOnly:        r.ReadOnly,
+			Reason:          r.Reason,
+			OwnerRestricted: r.OwnerRestricted,
+			ForceSendFields: []string{"ReadOnly"},
+		}}
+	}
+
+	updated, err := dc.service.Files.Update(fileID, f).
+		Context(ctx).
+		Fields(fileInfoFields).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update metadata: %w", err)
+	}
+	dc.invalidateMetadata(updated.Id, updated.Parents, updated.MimeType == folderMimeType)
+
+	info := newFileInfo(updated)
+	return &info, nil
+}
@@ -0,0 +1,217 @@
+package gdrive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// SniffLen is the number of leading bytes of a file passed to a MimeDetector.
+// It is larger than the 512 bytes used by http.DetectContentType so that the
+// entry names of ZIP based formats (DOCX, XLSX, PPTX, ODF, EPUB) can be inspected.
+const SniffLen = 8192
+
+// MimeDetector determines the MIME type of a file being uploaded.
+// head holds up to SniffLen leading bytes of the content and may be shorter.
+// Implementations must be safe for concurrent use.
+type MimeDetector interface {
+	DetectMimeType(fileName string, head []byte) string
+}
+
+// MimeDetectorFunc adapts an ordinary function to the MimeDetector interface.
+type MimeDetectorFunc func(fileName string, head []byte) string
+
+// DetectMimeType calls f(fileName, head).
+func (f MimeDetectorFunc) DetectMimeType(fileName string, head []byte) string {
+	return f(fileName, head)
+}
+
+// DefaultMimeDetector combines content sniffing with the file extension.
+// Detection happens in this order:
+//  1. Overrides, keyed by lower-case extension including the dot (e.g., ".log")
+//  2. Office Open XML, OpenDocument and EPUB signatures inside ZIP containers
+//  3. Specific signatures recognized by http.DetectContentType (PDF, PNG, MP4, ...)
+//  4. The file extension, using a built-in table and then mime.TypeByExtension
+//  5. The generic sniffed type ("text/plain", "application/zip", ...)
+//
+// Returned types never carry parameters such as "; charset=utf-8".
+type DefaultMimeDetector struct {
+	Overrides map[string]string
+}
+
+// genericSniffedTypes are sniffing results too vague to beat a known extension.
+var genericSniffedTypes = map[string]bool{
+	"application/octet-stream": true,
+	"application/zip":          true,
+	"application/xml":          true,
+	"text/plain":               true,
+	"text/xml":                 true,
+	"text/html":                true,
+}
+
+// extensionMimeTypes covers extensions missing from many system MIME databases.
+var extensionMimeTypes = map[string]string{
+	".csv":      "text/csv",
+	".tsv":      "text/tab-separated-values",
+	".json":     "application/json",
+	".md":       "text/markdown",
+	".markdown": "text/markdown",
+	".yaml":     "application/yaml",
+	".yml":      "application/yaml",
+	".svg":      "image/svg+xml",
+	".txt":      "text/plain",
+	".rtf":      "application/rtf",
+	".epub":     "application/epub+zip",
+	".doc":      "application/msword",
+	".xls":      "application/vnd.ms-excel",
+	".ppt":      "application/vnd.ms-powerpoint",
+	".docx":     "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx":     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx":     "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".odt":      "application/vnd.oasis.opendocument.text",
+	".ods":      "application/vnd.oasis.opendocument.spreadsheet",
+	".odp":      "application/vnd.oasis.opendocument.presentation",
+	".odg":      "application/vnd.oasis.opendocument.graphics",
+}
+
+// DetectMimeType implements MimeDetector.
+func (d DefaultMimeDetector) DetectMimeType(fileName string, head []byte) string {
+	ext := strings.ToLower(filepath.Ext(fileName))
+
+	if override, ok := d.Overrides[ext]; ok && ext != "" {
+		return override
+	}
+
+	if container := sniffZipContainer(head); container != "" {
+		return container
+	}
+
+	sniffed := "application/octet-stream"
+	if len(head) > 0 {
+		sniffed = stripMimeParams(http.DetectContentType(head))
+		if !genericSniffedTypes[sniffed] {
+			return sniffed
+		}
+	}
+
+	if ext != "" {
+		if t, ok := extensionMimeTypes[ext]; ok {
+			return t
+		}
+		if t := mime.TypeByExtension(ext); t != "" {
+			return stripMimeParams(t)
+		}
+	}
+	return sniffed
+}
+
+// DetectMimeType determines a MIME type using DefaultMimeDetector without overrides.
+//
+// Parameters:
+//   - fileName: Name of the file; only the extension is used
+//   - head: Leading bytes of the content, ideally SniffLen bytes
+//
+// Returns:
+//   - string: Detected MIME type, "application/octet-stream" if unknown
+//
+// Example:
+//
+//	head := make([]byte, gdrive.SniffLen)
+//	n, _ := io.ReadFull(file, head)
+//	mimeType := gdrive.DetectMimeType("report.docx", head[:n])
+//	// application/vnd.openxmlformats-officedocument.wordprocessingml.document
+func DetectMimeType(fileName string, head []byte) string {
+	return DefaultMimeDetector{}.DetectMimeType(fileName, head)
+}
+
+// SetMimeDetector replaces the detector used by UploadFile and UploadFileFromReader
+// (when mimeType is empty). UploadHandler takes its detector from
+// UploadHandlerOptions.MimeDetector instead. Passing nil restores DefaultMimeDetector.
+// It must be called before the client is shared between goroutines.
+//
+// Example:
+//
+//	client.SetMimeDetector(gdrive.DefaultMimeDetector{
+//	    Overrides: map[string]string{".log": "text/plain", ".parquet": "application/vnd.apache.parquet"},
+//	})
+func (dc *DriveClient) SetMimeDetector(d MimeDetector) {
+	dc.mimeDetector = d
+}
+
+// detectMimeType uses the configured detector, falling back to DefaultMimeDetector.
+func (dc *DriveClient) detectMimeType(fileName string, head []byte) string {
+	if dc.mimeDetector != nil {
+		if t := dc.mimeDetector.DetectMimeType(fileName, head); t != "" {
+			return t
+		}
+	}
+	return DefaultMimeDetector{}.DetectMimeType(fileName, head)
+}
+
+// sniffZipContainer recognizes ZIP based document formats from their leading bytes.
+// OpenDocument and EPUB store an uncompressed "mimetype" entry first, while Office
+// Open XML is identified by its top-level part directory names.
+// Returns an empty string if head is not one of these formats.
+func sniffZipContainer(head []byte) string {
+	if !bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		return ""
+	}
+
+	// Local file header: name length at offset 26, name at 30.
+	if len(head) > 38 && string(head[30:38]) == "mimetype" {
+		content := head[38:]
+		if end := bytes.Index(content, []byte("PK")); end > 0 {
+			content = content[:end]
+		}
+		if t := string(bytes.TrimSpace(content)); strings.HasPrefix(t, "application/") {
+			return t
+		}
+	}
+
+	for _, name := range zipEntryNames(head) {
+		switch {
+		case strings.HasPrefix(name, "word/"):
+			return extensionMimeTypes[".docx"]
+		case strings.HasPrefix(name, "xl/"):
+			return extensionMimeTypes[".xlsx"]
+		case strings.HasPrefix(name, "ppt/"):
+			return extensionMimeTypes[".pptx"]
+		}
+	}
+	return ""
+}
+
+// zipEntryNames returns the names of the local file headers found in head.
+// Headers are located by signature rather than by skipping compressed data,
+// because entries written with data descriptors do not record their size up front.
+func zipEntryNames(head []byte) []string {
+	var names []string
+	signature := []byte("PK\x03\x04")
+	for offset := 0; ; {
+		i := bytes.Index(head[offset:], signature)
+		if i < 0 {
+			return names
+		}
+		start := offset + i
+		if start+30 > len(head) {
+			return names
+		}
+		nameLen := int(binary.LittleEndian.Uint16(head[start+26:]))
+		if start+30+nameLen > len(head) {
+			return names
+		}
+		names = append(names, string(head[start+30:start+30+nameLen]))
+		offset = start + 30 + nameLen
+	}
+}
+
+// stripMimeParams removes parameters such as "; charset=utf-8" from a MIME type.
+func stripMimeParams(t string) string {
+	if mediaType, _, err := mime.ParseMediaType(t); err == nil {
+		return mediaType
+	}
+	return t
+}
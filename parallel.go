@@ -0,0 +1,450 @@
+package gdrive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// Defaults used by ParallelDownload when the corresponding option is zero.
+const (
+	DefaultParallelWorkers    = 4
+	DefaultParallelChunkSize  = 16 << 20 // 16 MiB
+	DefaultParallelMaxRetries = 3
+)
+
+// ParallelDownloadOptions configures a multi-range parallel download.
+// Zero values are replaced with the Default* constants above.
+type ParallelDownloadOptions struct {
+	Workers    int    // Number of concurrent range requests
+	ChunkSize  int64  // Size of each range in bytes
+	MaxRetries int    // Retries per chunk on rate limit, server and network errors
+	StateFile  string // Sidecar file recording completed chunks. Empty disables resume for ParallelDownload
+}
+
+// parallelState is the JSON content of the sidecar state file.
+// It is only reused when it describes the same file content and chunk layout.
+type parallelState struct {
+	FileID      string `json:"fileId"`
+	Size        int64  `json:"size"`
+	Md5Checksum string `json:"md5Checksum"`
+	ChunkSize   int64  `json:"chunkSize"`
+	Done        []bool `json:"done"`
+}
+
+// ParallelDownload downloads a file by splitting it into byte ranges and fetching
+// them concurrently. Each range is written at its offset in dst, so chunks may
+// complete in any order. A chunk that fails with a rate limit (429), server (5xx)
+// or network error is retried on its own with exponential backoff, without
+// restarting the other chunks. Other errors fail the download immediately.
+//
+// If opts.StateFile is set, completed chunks are recorded in that file and skipped
+// on the next call with the same file, which allows an interrupted download to resume.
+// The state is written by a single goroutine in batches, so workers never wait for
+// the disk. When dst is an *os.File it is synced before a batch is recorded, so the
+// state never claims data that is not on disk. The state is discarded automatically
+// if the file changed on Drive, and removed once the download completes.
+//
+// If dst also implements io.ReaderAt (as *os.File does), the assembled content is
+// verified against Drive's checksum once all chunks are written. An *os.File opened
+// write-only (os.O_WRONLY) cannot be read back, so verification is skipped for it;
+// open it with os.O_RDWR to have the content checked.
+//
+// Note: Google Workspace documents cannot be downloaded in ranges. Use ExportWorkspaceDocument instead.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: Unique Google Drive file identifier
+//   - dst: Destination supporting writes at arbitrary offsets (e.g., *os.File)
+//   - opts: Worker count, chunk size, retry limit and optional state file
+//
+// Returns:
+//   - int64: Size of the file in bytes
+//   - error: The first chunk error after retries are exhausted, or any metadata error.
+//     Wraps ErrChecksumMismatch if the content fails verification.
+//
+// Example:
+//
+//	out, _ := os.Create("/models/checkpoint.bin")
+//	defer out.Close()
+//	size, err := client.ParallelDownload(ctx, fileID, out, gdrive.ParallelDownloadOptions{
+//	    Workers:   8,
+//	    ChunkSize: 64 << 20,
+//	    StateFile: "/models/checkpoint.bin.state",
+//	})
+func (dc *DriveClient) ParallelDownload(ctx context.Context, fileID string, dst io.WriterAt, opts ParallelDownloadOptions) (int64, error) {
+	if fileID == "" {
+		return 0, errors.New("file ID cannot be empty")
+	}
+	if dst == nil {
+		return 0, errors.New("destination cannot be nil")
+	}
+
+	meta, err := dc.service.Files.Get(fileID).
+		Context(ctx).
+		Fields("id, mimeType, size, md5Checksum, sha256Checksum").
+		Do()
+	if err != nil {
+		return 0, fmt.Errorf("unable to get file metadata: %w", err)
+	}
+	if strings.HasPrefix(meta.MimeType, "application/vnd.google-apps.") {
+		return 0, fmt.Errorf("parallel downloads are not supported for Google Workspace documents (MIME type: %s)", meta.MimeType)
+	}
+
+	if err := dc.parallelDownload(ctx, fileID, meta.Size, meta.Md5Checksum, dst, opts); err != nil {
+		return 0, err
+	}
+
+	if ra, ok := dst.(io.ReaderAt); ok {
+		content := io.NewSectionReader(ra, 0, meta.Size)
+		err := compareChecksum(content, meta.Md5Checksum, meta.Sha256Checksum)
+		// A write-only file cannot be read back; there is nothing to verify.
+		if err != nil && !errors.Is(err, syscall.EBADF) {
+			if opts.StateFile != "" {
+				os.Remove(opts.StateFile)
+			}
+			return 0, err
+		}
+	}
+
+	if opts.StateFile != "" {
+		os.Remove(opts.StateFile)
+	}
+	return meta.Size, nil
+}
+
+// ParallelDownloadFile downloads a file to a local path using ParallelDownload.
+// Like DownloadFile, content is written to outputPath + ".partial", verified against
+// Drive's checksum, synced and then atomically renamed into place.
+// Resume is always enabled: if opts.StateFile is empty, outputPath + ".partial.state" is used.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: Unique Google Drive file identifier
+//   - outputPath: Local file system path where file will be saved
+//   - opts: Worker count, chunk size, retry limit and optional state file
+//
+// Returns:
+//   - int64: Size of the downloaded file in bytes
+//   - error: Any error encountered during download, verification or file creation.
+//     Wraps ErrChecksumMismatch if the content fails verification.
+//
+// Example:
+//
+//	size, err := client.ParallelDownloadFile(ctx, fileID, "/models/checkpoint.bin",
+//	    gdrive.ParallelDownloadOptions{Workers: 8})
+func (dc *DriveClient) ParallelDownloadFile(ctx context.Context, fileID, outputPath string, opts ParallelDownloadOptions) (int64, error) {
+	if fileID == "" {
+		return 0, errors.New("file ID cannot be empty")
+	}
+	if outputPath == "" {
+		return 0, errors.New("output path cannot be empty")
+	}
+
+	meta, err := dc.service.Files.Get(fileID).
+		Context(ctx).
+		Fields("id, mimeType, size, md5Checksum, sha256Checksum").
+		Do()
+	if err != nil {
+		return 0, fmt.Errorf("unable to get file metadata: %w", err)
+	}
+	if strings.HasPrefix(meta.MimeType, "application/vnd.google-apps.") {
+		return 0, fmt.Errorf("parallel downloads are not supported for Google Workspace documents (MIME type: %s)", meta.MimeType)
+	}
+
+	dir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("unable to create output directory: %w", err)
+	}
+
+	partialPath := outputPath + partialSuffix
+	if opts.StateFile == "" {
+		opts.StateFile = partialPath + ".state"
+	}
+
+	out, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("unable to create output file: %w", err)
+	}
+	defer out.Close()
+
+	if err := out.Truncate(meta.Size); err != nil {
+		return 0, fmt.Errorf("unable to allocate output file: %w", err)
+	}
+
+	if err := dc.parallelDownload(ctx, fileID, meta.Size, meta.Md5Checksum, out, opts); err != nil {
+		return 0, err
+	}
+
+	if err := verifyChecksum(out, meta.Md5Checksum, meta.Sha256Checksum); err != nil {
+		out.Close()
+		os.Remove(partialPath)
+		os.Remove(opts.StateFile)
+		return 0, err
+	}
+
+	if err := out.Sync(); err != nil {
+		return 0, fmt.Errorf("unable to sync output file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return 0, fmt.Errorf("unable to close output file: %w", err)
+	}
+	if err := os.Rename(partialPath, outputPath); err != nil {
+		return 0, fmt.Errorf("unable to move output file into place: %w", err)
+	}
+	syncDir(dir)
+	os.Remove(opts.StateFile)
+
+	return meta.Size, nil
+}
+
+// parallelDownload fetches all chunks of a file that are not yet recorded
+// as done in the state file and writes them into dst.
+func (dc *DriveClient) parallelDownload(ctx context.Context, fileID string, size int64, md5Sum string, dst io.WriterAt, opts ParallelDownloadOptions) error {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultParallelWorkers
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultParallelChunkSize
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = DefaultParallelMaxRetries
+	}
+	if size == 0 {
+		return nil
+	}
+
+	chunks := int((size + opts.ChunkSize - 1) / opts.ChunkSize)
+	state := loadParallelState(opts.StateFile, fileID, size, md5Sum, opts.ChunkSize, chunks)
+	pending := make([]int, 0, chunks)
+	for i, done := range state.Done {
+		if !done {
+			pending = append(pending, i)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+
+	// Completed chunks are recorded by a single goroutine, outside the workers.
+	var completed chan int
+	persisted := make(chan struct{})
+	if opts.StateFile != "" {
+		completed = make(chan int, len(pending))
+		go func() {
+			defer close(persisted)
+			persistParallelState(opts.StateFile, state, dst, completed)
+		}()
+	} else {
+		close(persisted)
+	}
+
+	jobs := make(chan int)
+	for range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				start := int64(i) * opts.ChunkSize
+				end := min(start+opts.ChunkSize, size) - 1
+
+				err := dc.downloadChunk(ctx, fileID, dst, start, end, opts.MaxRetries)
+				if err == nil {
+					if completed != nil {
+						completed <- i
+					}
+					continue
+				}
+
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("unable to download bytes %d-%d: %w", start, end, err)
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, i := range pending {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if completed != nil {
+		close(completed)
+	}
+	<-persisted
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// persistParallelState records chunks received from completed in the state file
+// until completed is closed. Chunks that arrive together are written as one batch:
+// dst is synced once and the state file rewritten once.
+func persistParallelState(path string, state *parallelState, dst io.WriterAt, completed <-chan int) {
+	for i := range completed {
+		batch := []int{i}
+	drain:
+		for {
+			select {
+			case j, ok := <-completed:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, j)
+			default:
+				break drain
+			}
+		}
+
+		if syncDestination(dst) != nil {
+			continue
+		}
+		for _, j := range batch {
+			state.Done[j] = true
+		}
+		saveParallelState(path, state)
+	}
+}
+
+// syncDestination flushes dst to stable storage when it is a file.
+// Other destinations have nothing to sync.
+func syncDestination(dst io.WriterAt) error {
+	if f, ok := dst.(*os.File); ok {
+		return f.Sync()
+	}
+	return nil
+}
+
+// downloadChunk fetches the inclusive byte range [start, end] and writes it at
+// offset start in dst, retrying retryable failures with exponential backoff.
+func (dc *DriveClient) downloadChunk(ctx context.Context, fileID string, dst io.WriterAt, start, end int64, maxRetries int) error {
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<(attempt-1)) * 500 * time.Millisecond
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err = dc.fetchRange(ctx, fileID, io.NewOffsetWriter(dst, start), start, end)
+		if err == nil || ctx.Err() != nil || !isRetryableError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// isRetryableError reports whether a failed request may succeed when repeated:
+// rate limiting (429), server errors (5xx) and network failures.
+func isRetryableError(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// fetchRange copies exactly the inclusive byte range [start, end] of a file to w.
+func (dc *DriveClient) fetchRange(ctx context.Context, fileID string, w io.Writer, start, end int64) error {
+	call := dc.service.Files.Get(fileID).Context(ctx)
+	call.Header().Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := call.Download()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent && (resp.StatusCode != http.StatusOK || start != 0) {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	want := end - start + 1
+	written, err := io.CopyN(w, resp.Body, want)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return fmt.Errorf("short read after %d of %d bytes: %w", written, want, err)
+	}
+	return nil
+}
+
+// loadParallelState reads the sidecar state file if it matches the current
+// file content and chunk layout. Otherwise a fresh state is returned.
+func loadParallelState(path, fileID string, size int64, md5Sum string, chunkSize int64, chunks int) *parallelState {
+	fresh := &parallelState{
+		FileID:      fileID,
+		Size:        size,
+		Md5Checksum: md5Sum,
+		ChunkSize:   chunkSize,
+		Done:        make([]bool, chunks),
+	}
+	if path == "" {
+		return fresh
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fresh
+	}
+
+	var state parallelState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fresh
+	}
+	if state.FileID != fileID || state.Size != size || state.Md5Checksum != md5Sum ||
+		state.ChunkSize != chunkSize || len(state.Done) != chunks {
+		return fresh
+	}
+	return &state
+}
+
+// saveParallelState writes the state atomically so that a crash never leaves
+// a half-written state file behind. Failures only cost resumability and are ignored.
+func saveParallelState(path string, state *parallelState) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	os.Rename(tmp, path)
+}
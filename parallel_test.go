@@ -0,0 +1,86 @@
+package gdrive_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/abiiranathan/gdrive"
+	"github.com/abiiranathan/gdrive/gdrivetest"
+)
+
+func TestParallelDownload(t *testing.T) {
+	srv, client := newClient(t)
+	content := pattern(10000)
+	id := srv.AddFile("data.bin", "", "", content)
+	path := filepath.Join(t.TempDir(), "data.bin")
+
+	n, err := client.ParallelDownloadFile(context.Background(), id, path, gdrive.ParallelDownloadOptions{
+		Workers:   3,
+		ChunkSize: 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(content)) {
+		t.Errorf("downloaded %d bytes, want %d", n, len(content))
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, content) {
+		t.Error("downloaded content does not match")
+	}
+}
+
+func TestParallelDownloadResume(t *testing.T) {
+	srv, client := newClient(t)
+	ctx := context.Background()
+	content := pattern(10000)
+	id := srv.AddFile("data.bin", "", "", content)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.bin")
+	opts := gdrive.ParallelDownloadOptions{
+		Workers:   3,
+		ChunkSize: 4000,
+		StateFile: filepath.Join(dir, "data.bin.state"),
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	// Chunks of 4000 bytes are cut off, the last chunk of 2000 bytes and the
+	// metadata response are not. The deadline ends the retries of the cut chunks.
+	srv.InjectFault(gdrivetest.Fault{Path: "/files/" + id, TruncateAfter: 2000})
+	interrupted, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.ParallelDownload(interrupted, id, out, opts); err == nil {
+		t.Fatal("interrupted download succeeded")
+	}
+	if _, err := os.Stat(opts.StateFile); err != nil {
+		t.Fatalf("no state file after an interrupted download: %v", err)
+	}
+
+	srv.ClearFaults()
+	srv.ResetRequests()
+	n, err := client.ParallelDownload(ctx, id, out, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(content)) {
+		t.Errorf("downloaded %d bytes, want %d", n, len(content))
+	}
+	// The metadata and the two interrupted chunks; the last chunk is not fetched again.
+	if got := countRequests(srv, "GET", "/drive/v3/files/"+id); got != 3 {
+		t.Errorf("%d requests on resume, want 3", got)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, content) {
+		t.Error("resumed content does not match")
+	}
+	if _, err := os.Stat(opts.StateFile); !os.IsNotExist(err) {
+		t.Errorf("state file kept after success: %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// PropertyScope selects one of the two custom property maps of a Drive file.
+// Its value is the Drive API field name, which is also used in search queries.
+type PropertyScope string
+
+// Custom property scopes.
+const (
+	// PublicProperties are visible to and editable by all apps with access to the file.
+	PublicProperties PropertyScope = "properties"
+	// AppProperties are private to the app (OAuth client) that created them.
+	AppProperties PropertyScope = "appProperties"
+)
+
+// propertyField returns the drive.File struct field name of a scope, as used
+// in ForceSendFields and NullFields.
+func (s PropertyScope) propertyField() (string, error) {
+	switch s {
+	case PublicProperties:
+		return "Properties", nil
+	case AppProperties:
+		return "AppProperties", nil
+	default:
+		return "", fmt.Errorf("unknown property scope %q", s)
+	}
+}
+
+// GetProperties retrieves the custom properties of a file in the given scope.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file
+//   - scope: gdrive.PublicProperties or gdrive.AppProperties
+//
+// Returns:
+//   - map[string]string: Properties; empty if none are set
+//   - error: Any error encountered during the API call
+//
+// Example:
+//
+//	props, err := client.GetProperties(ctx, fileID, gdrive.AppProperties)
+//	fmt.Println(props["pipelineRun"])
+func (dc *DriveClient) GetProperties(ctx context.Context, fileID string, scope PropertyScope) (map[string]string, error) {
+	if fileID == "" {
+		return nil, errors.New("file ID cannot be empty")
+	}
+	if _, err := scope.propertyField(); err != nil {
+		return nil, err
+	}
+
+	file, err := dc.service.Files.Get(fileID).
+		Context(ctx).
+		Fields("properties, appProperties").
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get properties: %w", err)
+	}
+
+	props := file.Properties
+	if scope == AppProperties {
+		props = file.AppProperties
+	}
+	if props == nil {
+		props = map[string]string{}
+	}
+	return props, nil
+}
+
+// SetProperties adds or overwrites custom properties of a file in the given scope.
+// Properties not named in props are left unchanged.
+//
+// Note: Drive limits each property to 124 bytes for key and value combined,
+// and a file to 30 public properties and 30 private properties per app.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file
+//   - scope: gdrive.PublicProperties or gdrive.AppProperties
+//   - props: Properties to set
+//
+// Returns:
+//   - map[string]string: All properties in the scope after the update
+//   - error: Any error encountered during the API call
+//
+// Example:
+//
+//	props, err := client.SetProperties(ctx, fileID, gdrive.AppProperties, map[string]string{
+//	    "pipelineRun": "2024-06-01T02:00:00Z",
+//	    "source":      "billing-export",
+//	})
+func (dc *DriveClient) SetProperties(ctx context.Context, fileID string, scope PropertyScope, props map[string]string) (map[string]string, error) {
+	if len(props) == 0 {
+		return dc.GetProperties(ctx, fileID, scope)
+	}
+
+	patch := &drive.File{}
+	if scope == AppProperties {
+		patch.AppProperties = props
+	} else {
+		patch.Properties = props
+	}
+	return dc.updateProperties(ctx, fileID, scope, patch)
+}
+
+// DeleteProperties removes custom properties of a file in the given scope.
+// Keys that are not set are ignored.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file
+//   - scope: gdrive.PublicProperties or gdrive.AppProperties
+//   - keys: Property keys to remove
+//
+// Returns:
+//   - map[string]string: All properties in the scope after the update
+//   - error: Any error encountered during the API call
+//
+// Example:
+//
+//	_, err := client.DeleteProperties(ctx, fileID, gdrive.PublicProperties, "reviewedBy", "reviewedAt")
+func (dc *DriveClient) DeleteProperties(ctx context.Context, fileID string, scope PropertyScope, keys ...string) (map[string]string, error) {
+	field, err := scope.propertyField()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return dc.GetProperties(ctx, fileID, scope)
+	}
+
+	// A key in NullFields is sent as null, which removes it from the map.
+	patch := &drive.File{}
+	for _, key := range keys {
+		patch.NullFields = append(patch.NullFields, field+"."+key)
+	}
+	return dc.updateProperties(ctx, fileID, scope, patch)
+}
+
+// updateProperties applies a property patch and returns the resulting properties of scope.
+func (dc *DriveClient) updateProperties(ctx context.Context, fileID string, scope PropertyScope, patch *drive.File) (map[string]string, error) {
+	if fileID == "" {
+		return nil, errors.New("file ID cannot be empty")
+	}
+	if _, err := scope.propertyField(); err != nil {
+		return nil, err
+	}
+
+	file, err := dc.service.Files.Update(fileID, patch).
+		Context(ctx).
+		Fields("properties, appProperties").
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update properties: %w", err)
+	}
+	dc.invalidateMetadata(fileID, nil, false)
+
+	props := file.Properties
+	if scope == AppProperties {
+		props = file.AppProperties
+	}
+	if props == nil {
+		props = map[string]string{}
+	}
+	return props, nil
+}
+
+// FindFilesByProperties finds non-trashed files whose custom properties in the
+// given scope contain every key/value pair of match, using Drive's
+// `properties has { key='k' and value='v' }` search syntax.
+// Files are retrieved in pages of MaxPageSize (100) items.
+//
+// Note: FolderPath is not populated in the results.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - scope: gdrive.PublicProperties or gdrive.AppProperties
+//   - match: Properties the files must have; must not be empty
+//
+// Returns:
+//   - []FileInfo: Matching files, including their properties
+//   - error: Any error encountered during API calls
+//
+// Example:
+//
+//	// All files produced by one pipeline run
+//	files, err := client.FindFilesByProperties(ctx, gdrive.AppProperties, map[string]string{
+//	    "pipelineRun": "2024-06-01T02:00:00Z",
+//	})
+func (dc *DriveClient) FindFilesByProperties(ctx context.Context, scope PropertyScope, match map[string]string) ([]FileInfo, error) {
+	if _, err := scope.propertyField(); err != nil {
+		return nil, err
+	}
+	if len(match) == 0 {
+		return nil, errors.New("at least one property is required")
+	}
+
+	// Sorted keys keep the query stable across calls.
+	clauses := make([]string, 0, len(match)+1)
+	for _, key := range slices.Sorted(maps.Keys(match)) {
+		clauses = append(clauses, fmt.Sprintf("%s has { key='%s' and value='%s' }",
+			scope, escapeQuery(key), escapeQuery(match[key])))
+	}
+	clauses = append(clauses, "trashed = false")
+	query := strings.Join(clauses, " and ")
+
+	files := make([]FileInfo, 0, MaxPageSize)
+	pageToken := ""
+
+	for {
+		call := dc.service.Files.List().
+			Context(ctx).
+			Q(query).
+			PageSize(MaxPageSize).
+			Fields("nextPageToken, files(" + fileInfoFields + ")")
+
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		r, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to search files: %w", err)
+		}
+
+		for _, f := range r.Files {
+			files = append(files, newFileInfo(f))
+		}
+
+		pageToken = r.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	return files, nil
+}
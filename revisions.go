@@ -0,0 +1,357 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// User identifies a Google Drive user, such as the last modifier of a revision.
+type User struct {
+	DisplayName  string // Name shown in the Drive UI
+	EmailAddress string // Email address; may be empty if not visible to the caller
+	PhotoLink    string // URL of the user's profile photo
+	Me           bool   // True if the user is the authenticated user
+}
+
+// Revision represents metadata about one version of a Google Drive file.
+type Revision struct {
+	ID                     string            // Revision identifier, usable with DownloadRevision
+	MimeType               string            // MIME type of the revision content
+	ModifiedTime           time.Time         // When the revision was created
+	Size                   int64             // Size in bytes (0 for Google Workspace documents)
+	Md5Checksum            string            // MD5 of the content (binary files only)
+	OriginalFilename       string            // Name of the uploaded file (binary files only)
+	KeepForever            bool              // Kept regardless of Drive's automatic revision pruning (binary files only)
+	Published              bool              // Published to the web (Workspace documents only)
+	PublishAuto            bool              // Later revisions are published automatically (Workspace documents only)
+	PublishedOutsideDomain bool              // Published outside the domain (Workspace documents only)
+	PublishedLink          string            // Link to the published revision
+	LastModifyingUser      *User             // User who created the revision; nil if unknown
+	ExportLinks            map[string]string // Export URLs by MIME type (Workspace documents only)
+}
+
+// revisionFields is the partial response selector for the fields used by newRevision.
+const revisionFields = "id, mimeType, modifiedTime, size, md5Checksum, originalFilename, keepForever, " +
+	"published, publishAuto, publishedOutsideDomain, publishedLink, lastModifyingUser, exportLinks"
+
+// newRevision converts Drive revision metadata to a Revision.
+func newRevision(r *drive.Revision) Revision {
+	modified, _ := time.Parse(time.RFC3339, r.ModifiedTime)
+	return Revision{
+		ID:                     r.Id,
+		MimeType:               r.MimeType,
+		ModifiedTime:           modified,
+		Size:                   r.Size,
+		Md5Checksum:            r.Md5Checksum,
+		OriginalFilename:       r.OriginalFilename,
+		KeepForever:            r.KeepForever,
+		Published:              r.Published,
+		PublishAuto:            r.PublishAuto,
+		PublishedOutsideDomain: r.PublishedOutsideDomain,
+		PublishedLink:          r.PublishedLink,
+		LastModifyingUser:      newUser(r.LastModifyingUser),
+		ExportLinks:            r.ExportLinks,
+	}
+}
+
+// newUser converts a Drive user to a User. Returns nil for a nil user.
+func newUser(u *drive.User) *User {
+	if u == nil {
+		return nil
+	}
+	return &User{
+		DisplayName:  u.DisplayName,
+		EmailAddress: u.EmailAddress,
+		PhotoLink:    u.PhotoLink,
+		Me:           u.Me,
+	}
+}
+
+// ListRevisions retrieves all revisions of a file, oldest first.
+// Revisions are retrieved in pages of MaxPageSize (100) items.
+//
+// Note: Drive may prune old revisions of binary files unless they are marked
+// KeepForever, and Workspace documents may merge revisions in this listing.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file
+//
+// Returns:
+//   - []Revision: Revision metadata, oldest first
+//   - error: Any error encountered during API calls
+//
+// Example:
+//
+//	revisions, err := client.ListRevisions(ctx, fileID)
+//	for _, rev := range revisions {
+//	    fmt.Printf("%s %s %d bytes keep=%t\n", rev.ID, rev.ModifiedTime, rev.Size, rev.KeepForever)
+//	}
+//	// Download the previous version
+//	prev := revisions[len(revisions)-2]
+//	client.DownloadRevision(ctx, fileID, prev.ID, &buf)
+func (dc *DriveClient) ListRevisions(ctx context.Context, fileID string) ([]Revision, error) {
+	if fileID == "" {
+		return nil, errors.New("file ID cannot be empty")
+	}
+
+	revisions := make([]Revision, 0, MaxPageSize)
+	pageToken := ""
+
+	for {
+		call := dc.service.Revisions.List(fileID).
+			Context(ctx).
+			PageSize(MaxPageSize).
+			Fields("nextPageToken, revisions(" + revisionFields + ")")
+
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		r, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list revisions: %w", err)
+		}
+
+		for _, rev := range r.Revisions {
+			revisions = append(revisions, newRevision(rev))
+		}
+
+		pageToken = r.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	return revisions, nil
+}
+
+// GetRevision retrieves the metadata of a single revision.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file
+//   - revisionID: ID of the revision
+//
+// Returns:
+//   - *Revision: Revision metadata
+//   - error: Any error encountered during the API call
+//
+// Example:
+//
+//	rev, err := client.GetRevision(ctx, fileID, revisionID)
+//	fmt.Printf("Modified by %s at %s\n", rev.LastModifyingUser.DisplayName, rev.ModifiedTime)
+func (dc *DriveClient) GetRevision(ctx context.Context, fileID, revisionID string) (*Revision, error) {
+	if fileID == "" {
+		return nil, errors.New("file ID cannot be empty")
+	}
+	if revisionID == "" {
+		return nil, errors.New("revision ID cannot be empty")
+	}
+
+	rev, err := dc.service.Revisions.Get(fileID, revisionID).
+		Context(ctx).
+		Fields(revisionFields).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get revision: %w", err)
+	}
+
+	revision := newRevision(rev)
+	return &revision, nil
+}
+
+// RevisionUpdate specifies revision settings to change.
+// Nil fields are left unchanged.
+type RevisionUpdate struct {
+	KeepForever            *bool // Keep the revision forever (binary files only)
+	Published              *bool // Publish the revision to the web (Workspace documents only)
+	PublishAuto            *bool // Publish later revisions automatically (Workspace documents only)
+	PublishedOutsideDomain *bool // Publish outside the domain (Workspace documents only)
+}
+
+// UpdateRevision changes the keepForever and publishing settings of a revision.
+//
+// Note: A file can have at most 200 revisions marked KeepForever.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file
+//   - revisionID: ID of the revision
+//   - update: Settings to change; nil fields are left unchanged
+//
+// Returns:
+//   - *Revision: Updated revision metadata
+//   - error: Any error encountered during the API call
+//
+// Example:
+//
+//	keep := true
+//	rev, err := client.UpdateRevision(ctx, fileID, revisionID, gdrive.RevisionUpdate{KeepForever: &keep})
+func (dc *DriveClient) UpdateRevision(ctx context.Context, fileID, revisionID string, update RevisionUpdate) (*Revision, error) {
+	if fileID == "" {
+		return nil, errors.New("file ID cannot be empty")
+	}
+	if revisionID == "" {
+		return nil, errors.New("revision ID cannot be empty")
+	}
+
+	patch := &drive.Revision{}
+	if update.KeepForever != nil {
+		patch.KeepForever = *update.KeepForever
+		patch.ForceSendFields = append(patch.ForceSendFields, "KeepForever")
+	}
+	if update.Published != nil {
+		patch.Published = *update.Published
+		patch.ForceSendFields = append(patch.ForceSendFields, "Published")
+	}
+	if update.PublishAuto != nil {
+		patch.PublishAuto = *update.PublishAuto
+		patch.ForceSendFields = append(patch.ForceSendFields, "PublishAuto")
+	}
+	if update.PublishedOutsideDomain != nil {
+		patch.PublishedOutsideDomain = *update.PublishedOutsideDomain
+		patch.ForceSendFields = append(patch.ForceSendFields, "PublishedOutsideDomain")
+	}
+
+	rev, err := dc.service.Revisions.Update(fileID, revisionID, patch).
+		Context(ctx).
+		Fields(revisionFields).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update revision: %w", err)
+	}
+
+	revision := newRevision(rev)
+	return &revision, nil
+}
+
+// DeleteRevision permanently deletes a revision of a binary file.
+// WARNING: This action is irreversible.
+//
+// Note: Revisions of Google Workspace documents cannot be deleted,
+// and the last remaining revision of a file cannot be deleted.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file
+//   - revisionID: ID of the revision to delete
+//
+// Returns:
+//   - error: Any error encountered during the operation
+//
+// Example:
+//
+//	err := client.DeleteRevision(ctx, fileID, revisionID)
+func (dc *DriveClient) DeleteRevision(ctx context.Context, fileID, revisionID string) error {
+	if fileID == "" {
+		return errors.New("file ID cannot be empty")
+	}
+	if revisionID == "" {
+		return errors.New("revision ID cannot be empty")
+	}
+
+	err := dc.service.Revisions.Delete(fileID, revisionID).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to delete revision: %w", err)
+	}
+
+	fmt.Printf("Revision permanently deleted: %s (file ID: %s)\n", revisionID, fileID)
+	return nil
+}
+
+// ErrWorkspaceRestore is returned by RestoreRevision for Google Workspace documents.
+// Their revisions can only be restored from the version history in the Drive UI.
+var ErrWorkspaceRestore = errors.New("revisions of Google Workspace documents cannot be restored through the API")
+
+// RestoreRevision rolls a binary file back to an earlier revision.
+// The revision's content is downloaded and uploaded again as the new head
+// revision, so the file keeps its ID, sharing permissions and links, and the
+// revisions in between remain in the history.
+//
+// The revision content is buffered in a temporary file and checked against the
+// revision's MD5 checksum, so that a failed or truncated download never becomes
+// the head revision.
+//
+// Note: The revision must still be available, i.e. marked KeepForever or
+// not yet pruned by Drive.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file to restore
+//   - revisionID: ID of the revision to restore (see ListRevisions)
+//
+// Returns:
+//   - *FileInfo: Metadata of the file after the restore (FolderPath is not populated)
+//   - error: Any error encountered. Wraps ErrWorkspaceRestore for Workspace documents,
+//     and ErrChecksumMismatch if the downloaded content does not match the revision
+//
+// Example:
+//
+//	revisions, _ := client.ListRevisions(ctx, fileID)
+//	// Undo the latest overwrite
+//	info, err := client.RestoreRevision(ctx, fileID, revisions[len(revisions)-2].ID)
+func (dc *DriveClient) RestoreRevision(ctx context.Context, fileID, revisionID string) (*FileInfo, error) {
+	if fileID == "" {
+		return nil, errors.New("file ID cannot be empty")
+	}
+	if revisionID == "" {
+		return nil, errors.New("revision ID cannot be empty")
+	}
+
+	file, err := dc.service.Files.Get(fileID).
+		Context(ctx).
+		Fields("mimeType").
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get file metadata: %w", err)
+	}
+	if strings.HasPrefix(file.MimeType, "application/vnd.google-apps.") {
+		return nil, fmt.Errorf("%w (MIME type: %s)", ErrWorkspaceRestore, file.MimeType)
+	}
+
+	rev, err := dc.GetRevision(ctx, fileID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "gdrive-revision-*")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := dc.DownloadRevision(ctx, fileID, revisionID, tmp); err != nil {
+		return nil, err
+	}
+	if err := verifyChecksum(tmp, rev.Md5Checksum, ""); err != nil {
+		return nil, fmt.Errorf("unable to verify revision content: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("unable to reset file pointer: %w", err)
+	}
+
+	updated, err := dc.service.Files.Update(fileID, &drive.File{}).
+		Context(ctx).
+		Media(tmp, googleapi.ContentType(rev.MimeType)).
+		Fields(fileInfoFields).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to restore revision: %w", err)
+	}
+	dc.invalidateMetadata(updated.Id, updated.Parents, false)
+
+	fmt.Printf("File restored to revision %s: %s (ID: %s)\n", revisionID, updated.Name, updated.Id)
+
+	info := newFileInfo(updated)
+	return &info, nil
+}
@@ -0,0 +1,56 @@
+package gdrive_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/abiiranathan/gdrive"
+)
+
+func TestRestoreRevision(t *testing.T) {
+	srv, client := newClient(t)
+	ctx := context.Background()
+	id := srv.AddFile("report.txt", "", "text/plain", []byte("v1"))
+	if _, err := client.UpdateFileContent(ctx, id, strings.NewReader("v2"), gdrive.UpdateContentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := client.ListRevisions(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("%d revisions, want 2", len(revisions))
+	}
+
+	info, err := client.RestoreRevision(ctx, id, revisions[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ID != id {
+		t.Errorf("restored file %s, want %s", info.ID, id)
+	}
+	if content, _ := srv.Content(id); string(content) != "v1" {
+		t.Errorf("content after restore %q, want v1", content)
+	}
+	// Restoring adds a revision rather than rewriting history.
+	if revisions, _ := client.ListRevisions(ctx, id); len(revisions) != 3 {
+		t.Errorf("%d revisions after restore, want 3", len(revisions))
+	}
+
+	if _, err := client.RestoreRevision(ctx, id, "missing"); err == nil {
+		t.Error("restoring a missing revision succeeded")
+	}
+}
+
+func TestRestoreRevisionWorkspace(t *testing.T) {
+	srv, client := newClient(t)
+	doc := srv.AddWorkspaceDocument("Doc", "", gdrive.WorkspaceDocument, nil)
+
+	_, err := client.RestoreRevision(context.Background(), doc, "1")
+	if !errors.Is(err, gdrive.ErrWorkspaceRestore) {
+		t.Errorf("restoring a Workspace document: %v, want ErrWorkspaceRestore", err)
+	}
+}
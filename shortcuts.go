@@ -0,0 +1,250 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// ErrShortcutCycle is returned when following shortcuts leads back to a
+// shortcut already visited, or exceeds the maximum number of hops.
+var ErrShortcutCycle = errors.New("shortcut cycle or chain too long")
+
+// ErrPathNotFound is returned by ResolvePath when a path segment does not exist.
+var ErrPathNotFound = errors.New("path not found")
+
+// ErrBrokenShortcut is returned when a shortcut has no target.
+var ErrBrokenShortcut = errors.New("shortcut has no target")
+
+// ShortcutDetails describes the target of a shortcut.
+type ShortcutDetails struct {
+	TargetID       string // ID of the file or folder the shortcut points to
+	TargetMimeType string // MIME type of the target at the time the shortcut was last updated
+}
+
+// newShortcutDetails converts Drive shortcut details. Returns nil for nil details.
+func newShortcutDetails(d *drive.FileShortcutDetails) *ShortcutDetails {
+	if d == nil {
+		return nil
+	}
+	return &ShortcutDetails{
+		TargetID:       d.TargetId,
+		TargetMimeType: d.TargetMimeType,
+	}
+}
+
+// CreateShortcut creates a shortcut to a file or folder.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - targetID: ID of the file or folder to point to
+//   - name: Display name of the shortcut. If empty, uses the target's name
+//   - parentFolderID: ID of the folder to create the shortcut in. Empty string uses "My Drive" root
+//
+// Returns:
+//   - *FileInfo: Metadata of the shortcut, with Shortcut set (FolderPath is not populated)
+//   - error: Any error encountered during the API calls
+//
+// Example:
+//
+//	// Make a shared folder reachable from a project folder
+//	shortcut, err := client.CreateShortcut(ctx, sharedFolderID, "", projectFolderID)
+func (dc *DriveClient) CreateShortcut(ctx context.Context, targetID, name, parentFolderID string) (*FileInfo, error) {
+	if targetID == "" {
+		return nil, errors.New("target ID cannot be empty")
+	}
+
+	if name == "" {
+		target, err := dc.service.Files.Get(targetID).
+			Context(ctx).
+			Fields("name").
+			Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get target metadata: %w", err)
+		}
+		name = target.Name
+	}
+
+	meta := &drive.File{
+		Name:            name,
+		MimeType:        shortcutMimeType,
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: targetID},
+	}
+	if parentFolderID != "" {
+		meta.Parents = []string{parentFolderID}
+	}
+
+	created, err := dc.service.Files.Create(meta).
+		Context(ctx).
+		Fields(fileInfoFields).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create shortcut: %w", err)
+	}
+	dc.invalidateMetadata(created.Id, created.Parents, false)
+
+	fmt.Printf("Shortcut created: %s -> %s (ID: %s)\n", created.Name, targetID, created.Id)
+
+	info := newFileInfo(created)
+	return &info, nil
+}
+
+// ResolveShortcut returns the metadata of the file or folder a shortcut points
+// to, following chains of shortcuts. Any other file is returned unchanged.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of a shortcut or any other file
+//
+// Returns:
+//   - *FileInfo: Metadata of the final target (FolderPath is not populated)
+//   - error: Any error encountered. Wraps ErrShortcutCycle if the chain loops
+//     or is longer than 10 shortcuts, and ErrBrokenShortcut if a shortcut has no target
+//
+// Example:
+//
+//	target, err := client.ResolveShortcut(ctx, shortcutID)
+//	fmt.Printf("points to %s (%s)\n", target.Name, target.MimeType)
+func (dc *DriveClient) ResolveShortcut(ctx context.Context, fileID string) (*FileInfo, error) {
+	if fileID == "" {
+		return nil, errors.New("file ID cannot be empty")
+	}
+	return resolveShortcut(ctx, dc, fileID)
+}
+
+// resolveShortcut follows a chain of shortcuts using l for metadata lookups.
+func resolveShortcut(ctx context.Context, l Lister, fileID string) (*FileInfo, error) {
+	visited := make(map[string]bool)
+	currentID := fileID
+	for {
+		if visited[currentID] || len(visited) > maxShortcutHops {
+			return nil, fmt.Errorf("%w: starting at %s", ErrShortcutCycle, fileID)
+		}
+		visited[currentID] = true
+
+		info, err := l.GetFile(ctx, currentID)
+		if err != nil {
+			return nil, err
+		}
+		if info.MimeType != shortcutMimeType {
+			return info, nil
+		}
+		if info.Shortcut == nil || info.Shortcut.TargetID == "" {
+			return nil, fmt.Errorf("%w: %s", ErrBrokenShortcut, info.ID)
+		}
+		currentID = info.Shortcut.TargetID
+	}
+}
+
+// ResolvePath looks up a file or folder by its slash-separated path, such as
+// "Projects/2024/report.pdf". Lookup starts at the "My Drive" root; a leading
+// "/" or "My Drive" segment is ignored. Shortcuts to folders are traversed
+// like folders; a shortcut in the last segment is returned as is.
+//
+// Note: If a folder contains several items with the same name, the first one
+// returned by Drive is used.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - path: Slash-separated path of display names
+//
+// Returns:
+//   - *FileInfo: Metadata of the item at path (FolderPath is not populated)
+//   - error: Any error encountered. Wraps ErrPathNotFound if a segment does not
+//     exist, and ErrShortcutCycle if shortcuts loop
+//
+// Example:
+//
+//	info, err := client.ResolvePath(ctx, "My Drive/Projects/2024/report.pdf")
+//	if errors.Is(err, gdrive.ErrPathNotFound) {
+//	    // Create it
+//	}
+func (dc *DriveClient) ResolvePath(ctx context.Context, path string) (*FileInfo, error) {
+	var segments []string
+	for segment := range strings.SplitSeq(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) > 0 && segments[0] == "My Drive" {
+		segments = segments[1:]
+	}
+	if len(segments) == 0 {
+		return dc.GetFile(ctx, "root")
+	}
+
+	folderID := "root"
+	for i, segment := range segments[:len(segments)-1] {
+		folder, err := dc.findChild(ctx, folderID, segment, true)
+		if err != nil {
+			return nil, err
+		}
+		if folder == nil {
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, strings.Join(segments[:i+1], "/"))
+		}
+		folderID = folder.ID
+	}
+
+	info, err := dc.findChild(ctx, folderID, segments[len(segments)-1], false)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, strings.Join(segments, "/"))
+	}
+	return info, nil
+}
+
+// findChild returns the first non-trashed item named name in a folder, or nil
+// if there is none. With traversable set, only folders and shortcuts resolving
+// to folders match, and the folder itself is returned.
+func (dc *DriveClient) findChild(ctx context.Context, folderID, name string, traversable bool) (*FileInfo, error) {
+	query := fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false",
+		escapeQuery(name), escapeQuery(folderID))
+	if traversable {
+		query += fmt.Sprintf(" and (mimeType = '%s' or mimeType = '%s')", folderMimeType, shortcutMimeType)
+	}
+
+	pageToken := ""
+	for {
+		call := dc.service.Files.List().
+			Context(ctx).
+			Q(query).
+			PageSize(MaxPageSize).
+			Fields("nextPageToken, files(" + fileInfoFields + ")")
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		r, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to look up %q: %w", name, err)
+		}
+
+		for _, f := range r.Files {
+			info := newFileInfo(f)
+			if !traversable || info.MimeType == folderMimeType {
+				return &info, nil
+			}
+			if info.Shortcut == nil || info.Shortcut.TargetMimeType != folderMimeType {
+				continue
+			}
+			target, err := dc.ResolveShortcut(ctx, info.ID)
+			if err != nil {
+				return nil, err
+			}
+			if target.MimeType == folderMimeType {
+				return target, nil
+			}
+		}
+
+		pageToken = r.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+	return nil, nil
+}
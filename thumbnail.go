@@ -0,0 +1,315 @@
+package gdrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// ErrNoThumbnail is returned when Drive has not generated a thumbnail for a file.
+var ErrNoThumbnail = errors.New("file has no thumbnail")
+
+// Thumbnail sizes in pixels of the longest side.
+const (
+	DefaultThumbnailSize = 220  // Size Drive links to
+	MaxThumbnailSize     = 1600 // Largest size Drive serves
+)
+
+// MaxCustomThumbnailBytes is the largest custom thumbnail image Drive accepts
+// with an upload (see UploadOptions.Thumbnail).
+const MaxCustomThumbnailBytes = 2 << 20 // 2 MB
+
+// thumbnailSizeSuffix matches the size parameter at the end of a thumbnailLink, e.g. "=s220".
+var thumbnailSizeSuffix = regexp.MustCompile(`=s\d+$`)
+
+// thumbnailURL returns link with its size parameter set to size pixels.
+// A size of zero or less keeps the link unchanged.
+func thumbnailURL(link string, size int) string {
+	if size <= 0 {
+		return link
+	}
+	suffix := "=s" + strconv.Itoa(size)
+	if thumbnailSizeSuffix.MatchString(link) {
+		return thumbnailSizeSuffix.ReplaceAllString(link, suffix)
+	}
+	return link + suffix
+}
+
+// validThumbnailSize returns an error unless size is zero (the default size)
+// or between 1 and MaxThumbnailSize.
+func validThumbnailSize(size int) error {
+	if size < 0 || size > MaxThumbnailSize {
+		return fmt.Errorf("thumbnail size must be between 1 and %d pixels, got %d", MaxThumbnailSize, size)
+	}
+	return nil
+}
+
+// openThumbnail fetches the thumbnail behind link in the given size.
+// fileID is only used in error messages. The caller must close the response body.
+func (dc *DriveClient) openThumbnail(ctx context.Context, fileID, link string, size int) (*http.Response, error) {
+	if err := validThumbnailSize(size); err != nil {
+		return nil, err
+	}
+	if link == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoThumbnail, fileID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, thumbnailURL(link, size), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create thumbnail request: %w", err)
+	}
+
+	resp, err := dc.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download thumbnail: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrNoThumbnail, fileID)
+		}
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// GetThumbnail streams the thumbnail image of a file to w.
+// Thumbnails are fetched through the thumbnailLink of the file's metadata,
+// which is short-lived, so it is looked up on every call.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file
+//   - size: Length of the longest side in pixels, at most MaxThumbnailSize; zero uses DefaultThumbnailSize
+//   - w: Destination writer for the image
+//
+// Returns:
+//   - int64: Number of bytes written
+//   - string: Content type of the image (e.g., "image/png")
+//   - error: Any error encountered. Wraps ErrNoThumbnail if Drive has no thumbnail for the file.
+//     Sizes outside 1..MaxThumbnailSize are rejected before any request is made
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//	_, contentType, err := client.GetThumbnail(ctx, fileID, 400, &buf)
+//	if errors.Is(err, gdrive.ErrNoThumbnail) {
+//	    // Show a generic icon
+//	}
+func (dc *DriveClient) GetThumbnail(ctx context.Context, fileID string, size int, w io.Writer) (int64, string, error) {
+	if fileID == "" {
+		return 0, "", errors.New("file ID cannot be empty")
+	}
+	if err := validThumbnailSize(size); err != nil {
+		return 0, "", err
+	}
+
+	file, err := dc.service.Files.Get(fileID).
+		Context(ctx).
+		Fields("id, thumbnailLink").
+		Do()
+	if err != nil {
+		return 0, "", fmt.Errorf("unable to get file metadata: %w", err)
+	}
+
+	return dc.streamThumbnail(ctx, file.Id, file.ThumbnailLink, size, w)
+}
+
+// GetFileThumbnail is like GetThumbnail for a file whose metadata was already
+// fetched with GetFile, which saves the metadata request. The thumbnailLink is
+// short-lived, so file should be recent.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - file: Metadata returned by GetFile
+//   - size: Length of the longest side in pixels, at most MaxThumbnailSize; zero uses DefaultThumbnailSize
+//   - w: Destination writer for the image
+//
+// Returns:
+//   - int64: Number of bytes written
+//   - string: Content type of the image (e.g., "image/png")
+//   - error: Any error encountered. Wraps ErrNoThumbnail if file has no ThumbnailLink
+//     or Drive has no thumbnail for it
+//
+// Example:
+//
+//	info, _ := client.GetFile(ctx, fileID)
+//	var buf bytes.Buffer
+//	_, contentType, err := client.GetFileThumbnail(ctx, info, 400, &buf)
+func (dc *DriveClient) GetFileThumbnail(ctx context.Context, file *FileInfo, size int, w io.Writer) (int64, string, error) {
+	if file == nil {
+		return 0, "", errors.New("file cannot be nil")
+	}
+	return dc.streamThumbnail(ctx, file.ID, file.ThumbnailLink, size, w)
+}
+
+// streamThumbnail copies the thumbnail behind link to w.
+func (dc *DriveClient) streamThumbnail(ctx context.Context, fileID, link string, size int, w io.Writer) (int64, string, error) {
+	resp, err := dc.openThumbnail(ctx, fileID, link, size)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	written, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return written, "", fmt.Errorf("unable to write thumbnail: %w", err)
+	}
+	return written, resp.Header.Get("Content-Type"), nil
+}
+
+// contentHintsThumbnail builds the contentHints of an upload with a custom thumbnail.
+// Returns nil if image is empty, and an error if it exceeds MaxCustomThumbnailBytes.
+func contentHintsThumbnail(image []byte, mimeType string) (*drive.FileContentHints, error) {
+	if len(image) == 0 {
+		return nil, nil
+	}
+	if len(image) > MaxCustomThumbnailBytes {
+		return nil, fmt.Errorf("custom thumbnail of %d bytes exceeds the limit of %d bytes", len(image), MaxCustomThumbnailBytes)
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(image)
+	}
+	return &drive.FileContentHints{
+		Thumbnail: &drive.FileContentHintsThumbnail{
+			Image:    base64.URLEncoding.EncodeToString(image),
+			MimeType: mimeType,
+		},
+	}, nil
+}
+
+// ThumbnailHandlerOptions configures the handler returned by ThumbnailHandler.
+type ThumbnailHandlerOptions struct {
+	// PathValue is the name of the route wildcard holding the file ID. Defaults to "id".
+	// If the wildcard is missing or empty, the "id" query parameter is used instead.
+	PathValue string
+
+	// MaxSize caps the "size" query parameter, in pixels. Defaults to and is
+	// limited to MaxThumbnailSize.
+	MaxSize int
+
+	// CacheControl is sent as the Cache-Control header. Defaults to "private, max-age=3600".
+	CacheControl string
+
+	// ErrorLog receives errors that occur after the response has started.
+	// If nil, these errors are not logged.
+	ErrorLog *log.Logger
+}
+
+// ThumbnailHandler returns an http.Handler that serves file thumbnails by ID.
+// The size is taken from the "size" query parameter (default DefaultThumbnailSize).
+//
+// Each request reads the file metadata once with GetFile and fetches the image
+// with GetFileThumbnail. Responses carry an ETag derived from the file version
+// and size, plus Last-Modified and Cache-Control headers; conditional requests
+// are answered with 304 Not Modified without downloading the image. Files
+// without a thumbnail are reported as 404 Not Found.
+//
+// Parameters:
+//   - client: Client used to fetch metadata and thumbnails, usually a *DriveClient
+//   - opts: Route, size limit and caching options
+//
+// Returns:
+//   - http.Handler: Handler serving thumbnails by ID
+//
+// Example:
+//
+//	mux.Handle("GET /thumbnails/{id}", gdrive.ThumbnailHandler(client, gdrive.ThumbnailHandlerOptions{}))
+//	// <img src="/thumbnails/1aBc2DeF?size=320">
+func ThumbnailHandler(client ThumbnailHandlerClient, opts ThumbnailHandlerOptions) http.Handler {
+	if opts.PathValue == "" {
+		opts.PathValue = "id"
+	}
+	if opts.MaxSize <= 0 || opts.MaxSize > MaxThumbnailSize {
+		opts.MaxSize = MaxThumbnailSize
+	}
+	if opts.CacheControl == "" {
+		opts.CacheControl = "private, max-age=3600"
+	}
+	return &thumbnailHandler{client: client, opts: opts}
+}
+
+type thumbnailHandler struct {
+	client ThumbnailHandlerClient
+	opts   ThumbnailHandlerOptions
+}
+
+func (th *thumbnailHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fileID := r.PathValue(th.opts.PathValue)
+	if fileID == "" {
+		fileID = r.URL.Query().Get("id")
+	}
+	if fileID == "" {
+		http.Error(w, "File ID required", http.StatusBadRequest)
+		return
+	}
+
+	size := DefaultThumbnailSize
+	if s := r.URL.Query().Get("size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			http.Error(w, "Invalid size: "+s, http.StatusBadRequest)
+			return
+		}
+		size = min(n, th.opts.MaxSize)
+	}
+
+	ctx := r.Context()
+	file, err := th.client.GetFile(ctx, fileID)
+	if err != nil {
+		http.Error(w, http.StatusText(httpStatusFromError(err)), httpStatusFromError(err))
+		return
+	}
+
+	modTime := file.ModifiedTime
+	etag := fmt.Sprintf(`"v%d-s%d"`, file.Version, size)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", th.opts.CacheControl)
+	if !modTime.IsZero() {
+		w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, modTime) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// Thumbnails are small; buffering lets errors be reported with a status code.
+	var image bytes.Buffer
+	_, contentType, err := th.client.GetFileThumbnail(ctx, file, size, &image)
+	if err != nil {
+		status := httpStatusFromError(err)
+		if errors.Is(err, ErrNoThumbnail) {
+			status = http.StatusNotFound
+		}
+		w.Header().Del("ETag")
+		w.Header().Del("Last-Modified")
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(image.Len()))
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := image.WriteTo(w); err != nil && th.opts.ErrorLog != nil {
+		th.opts.ErrorLog.Printf("gdrive: unable to write thumbnail of %s: %v", fileID, err)
+	}
+}
@@ -0,0 +1,39 @@
+package gdrive_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/abiiranathan/gdrive"
+)
+
+func TestThumbnailHandler(t *testing.T) {
+	srv, client := newClient(t)
+	id := srv.AddFile("a.png", "", "image/png", pngHeader)
+	folder := srv.AddFolder("Folder", "")
+	handler := gdrive.ThumbnailHandler(client, gdrive.ThumbnailHandlerOptions{})
+
+	srv.ResetRequests()
+	rec := serve(handler, http.MethodGet, "/files/"+id+"?size=100", nil)
+	if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+		t.Fatalf("status %d, %d bytes", rec.Code, rec.Body.Len())
+	}
+	// One metadata request and the image itself.
+	if got := srv.Requests(); len(got) != 2 {
+		t.Errorf("requests %q, want 2", got)
+	}
+
+	etag := rec.Header().Get("ETag")
+	srv.ResetRequests()
+	rec = serve(handler, http.MethodGet, "/files/"+id+"?size=100", map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: status %d, want 304", rec.Code)
+	}
+	if got := srv.Requests(); len(got) != 1 {
+		t.Errorf("requests for a 304 %q, want only the metadata", got)
+	}
+
+	if rec := serve(handler, http.MethodGet, "/files/"+folder, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("folder without a thumbnail: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,248 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// ErrNotConfirmed is returned by destructive bulk operations that were not
+// explicitly confirmed through their options.
+var ErrNotConfirmed = errors.New("operation not confirmed")
+
+// TrashedFile describes an item in the trash.
+type TrashedFile struct {
+	FileInfo
+	TrashedTime       time.Time // When the item was trashed; zero if not reported
+	TrashingUser      *User     // User who trashed the item; nil if unknown
+	ExplicitlyTrashed bool      // False if the item is only trashed because a parent folder is
+}
+
+// trashedFileFields is the partial response selector for the fields used by newTrashedFile.
+const trashedFileFields = fileInfoFields + ", trashedTime, trashingUser, explicitlyTrashed"
+
+// newTrashedFile converts Drive file metadata to a TrashedFile.
+func newTrashedFile(f *drive.File) TrashedFile {
+	trashed, _ := time.Parse(time.RFC3339, f.TrashedTime)
+	return TrashedFile{
+		FileInfo:          newFileInfo(f),
+		TrashedTime:       trashed,
+		TrashingUser:      newUser(f.TrashingUser),
+		ExplicitlyTrashed: f.ExplicitlyTrashed,
+	}
+}
+
+// ListTrashPage retrieves one page of trashed items, most recently modified first.
+// Drive cannot sort by trash time.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - pageSize: Maximum number of items; values outside 1-MaxPageSize use MaxPageSize
+//   - pageToken: Token from a previous call, or empty for the first page
+//
+// Returns:
+//   - []TrashedFile: Trashed items of this page (FolderPath is not populated)
+//   - string: Token for the next page; empty if this was the last page
+//   - error: Any error encountered during the API call
+//
+// Example:
+//
+//	token := ""
+//	for {
+//	    items, next, err := client.ListTrashPage(ctx, 50, token)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    show(items)
+//	    if token = next; token == "" {
+//	        break
+//	    }
+//	}
+func (dc *DriveClient) ListTrashPage(ctx context.Context, pageSize int, pageToken string) ([]TrashedFile, string, error) {
+	if pageSize <= 0 || pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+
+	call := dc.service.Files.List().
+		Context(ctx).
+		Q("trashed = true").
+		OrderBy("modifiedTime desc").
+		PageSize(int64(pageSize)).
+		Fields("nextPageToken, files(" + trashedFileFields + ")")
+
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+
+	r, err := call.Do()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to list trash: %w", err)
+	}
+
+	items := make([]TrashedFile, 0, len(r.Files))
+	for _, f := range r.Files {
+		items = append(items, newTrashedFile(f))
+	}
+	return items, r.NextPageToken, nil
+}
+
+// ListTrash retrieves all trashed items visible to the caller, including items
+// that are only trashed because a parent folder is (see ExplicitlyTrashed).
+// Items are retrieved in pages of MaxPageSize (100) items.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//
+// Returns:
+//   - []TrashedFile: Trashed items (FolderPath is not populated)
+//   - error: Any error encountered during API calls
+//
+// Example:
+//
+//	items, err := client.ListTrash(ctx)
+//	for _, item := range items {
+//	    fmt.Printf("%s trashed at %s\n", item.Name, item.TrashedTime)
+//	}
+func (dc *DriveClient) ListTrash(ctx context.Context) ([]TrashedFile, error) {
+	items := make([]TrashedFile, 0, MaxPageSize)
+	pageToken := ""
+
+	for {
+		page, next, err := dc.ListTrashPage(ctx, MaxPageSize, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+
+		pageToken = next
+		if pageToken == "" {
+			break
+		}
+	}
+
+	return items, nil
+}
+
+// EmptyTrashOptions configures EmptyTrash.
+type EmptyTrashOptions struct {
+	// Confirm must be true for the trash to be emptied.
+	Confirm bool
+}
+
+// EmptyTrash permanently deletes all items in the caller's trash.
+// WARNING: This action is irreversible.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - opts: Must have Confirm set
+//
+// Returns:
+//   - error: ErrNotConfirmed if opts.Confirm is false, or any error encountered during the operation
+//
+// Example:
+//
+//	err := client.EmptyTrash(ctx, gdrive.EmptyTrashOptions{Confirm: true})
+func (dc *DriveClient) EmptyTrash(ctx context.Context, opts EmptyTrashOptions) error {
+	if !opts.Confirm {
+		return fmt.Errorf("%w: set EmptyTrashOptions.Confirm to empty the trash", ErrNotConfirmed)
+	}
+
+	err := dc.service.Files.EmptyTrash().Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to empty trash: %w", err)
+	}
+	// The deleted items are not known individually, so drop everything.
+	if c := dc.metadataCache(); c != nil {
+		c.Purge()
+	}
+
+	fmt.Println("Trash emptied")
+	return nil
+}
+
+// ItemResult is the outcome of a bulk operation for a single item.
+type ItemResult struct {
+	FileID string // ID of the item
+	Err    error  // nil on success
+}
+
+// TrashMany moves several files or folders to the trash.
+// A failure on one item does not stop the others.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileIDs: IDs of the items to trash
+//
+// Returns:
+//   - []ItemResult: One result per ID, in the order of fileIDs
+//
+// Example:
+//
+//	for _, r := range client.TrashMany(ctx, ids) {
+//	    if r.Err != nil {
+//	        log.Printf("%s: %v", r.FileID, r.Err)
+//	    }
+//	}
+func (dc *DriveClient) TrashMany(ctx context.Context, fileIDs []string) []ItemResult {
+	results := make([]ItemResult, 0, len(fileIDs))
+	for _, id := range fileIDs {
+		results = append(results, ItemResult{FileID: id, Err: dc.TrashFile(ctx, id)})
+	}
+	return results
+}
+
+// RestoreMany restores several files or folders from the trash.
+// A failure on one item does not stop the others.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileIDs: IDs of the items to restore
+//
+// Returns:
+//   - []ItemResult: One result per ID, in the order of fileIDs
+//
+// Example:
+//
+//	results := client.RestoreMany(ctx, ids)
+func (dc *DriveClient) RestoreMany(ctx context.Context, fileIDs []string) []ItemResult {
+	results := make([]ItemResult, 0, len(fileIDs))
+	for _, id := range fileIDs {
+		results = append(results, ItemResult{FileID: id, Err: dc.RestoreFile(ctx, id)})
+	}
+	return results
+}
+
+// RestoreTrashedSince restores every item explicitly trashed at or after since,
+// for example to undo an accidental mass delete by a script. Items that are only
+// trashed because their folder is come back with the folder and are not restored
+// individually.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - since: Earliest trash time to restore
+//
+// Returns:
+//   - []ItemResult: One result per restored item
+//   - error: Any error encountered while listing the trash
+//
+// Example:
+//
+//	// Undo everything trashed in the last hour
+//	results, err := client.RestoreTrashedSince(ctx, time.Now().Add(-time.Hour))
+func (dc *DriveClient) RestoreTrashedSince(ctx context.Context, since time.Time) ([]ItemResult, error) {
+	items, err := dc.ListTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, item := range items {
+		if item.ExplicitlyTrashed && !item.TrashedTime.Before(since) {
+			ids = append(ids, item.ID)
+		}
+	}
+	return dc.RestoreMany(ctx, ids), nil
+}
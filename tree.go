@@ -0,0 +1,301 @@
+package gdrive
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// TreeItem is a file or folder in a TreePlan.
+type TreeItem struct {
+	ID         string // File or folder ID
+	Name       string // Display name
+	MimeType   string // MIME type
+	ParentID   string // ID of the folder the item was found in; empty for the root
+	Depth      int    // Distance from the root; 0 for the root
+	Skipped    bool   // True if the operation leaves the item untouched
+	SkipReason string // Why the item is skipped
+}
+
+// TreePlan lists the items a DeleteTree or TrashTree operation covers.
+type TreePlan struct {
+	RootID string // ID of the root of the subtree
+
+	// Items holds every item of the subtree, deepest first, so that a folder
+	// always follows its content. The root is last.
+	Items []TreeItem
+
+	// ConfirmToken identifies the exact set of items the operation would act on.
+	// Passing it back in TreeOptions.ConfirmToken confirms the operation; if the
+	// subtree changes in the meantime, the token no longer matches.
+	ConfirmToken string
+}
+
+// Actionable returns the items that are not skipped, in plan order.
+func (p *TreePlan) Actionable() []TreeItem {
+	items := make([]TreeItem, 0, len(p.Items))
+	for _, item := range p.Items {
+		if !item.Skipped {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// TreeOptions configures DeleteTree and TrashTree.
+// Unless DryRun is set, the operation must be confirmed with ConfirmToken or MaxItems.
+type TreeOptions struct {
+	// DryRun returns the plan without changing anything.
+	DryRun bool
+
+	// ConfirmToken must equal the ConfirmToken of a plan obtained from PlanTree
+	// or a dry run of the same subtree.
+	ConfirmToken string
+
+	// MaxItems confirms the operation if it acts on at most this many items.
+	// Zero disables this form of confirmation.
+	MaxItems int
+}
+
+// PlanTree enumerates the subtree rooted at rootID without changing anything.
+// Items not owned by the caller are skipped, and so are the folders that
+// contain skipped items, directly or further down, because removing such a
+// folder would take the skipped items with it. Shortcuts are planned as items
+// and never followed.
+//
+// Note: Items in shared drives are owned by the drive, not the caller, so
+// they are always skipped.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - rootID: ID of the file or folder at the root of the subtree
+//
+// Returns:
+//   - *TreePlan: Items of the subtree, deepest first, and the confirmation token
+//   - error: Any error encountered during API calls
+//
+// Example:
+//
+//	plan, err := client.PlanTree(ctx, folderID)
+//	for _, item := range plan.Items {
+//	    fmt.Printf("%s skipped=%t %s\n", item.Name, item.Skipped, item.SkipReason)
+//	}
+func (dc *DriveClient) PlanTree(ctx context.Context, rootID string) (*TreePlan, error) {
+	if rootID == "" {
+		return nil, errors.New("root ID cannot be empty")
+	}
+
+	root, err := dc.service.Files.Get(rootID).
+		Context(ctx).
+		Fields("id, name, mimeType, ownedByMe").
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get root metadata: %w", err)
+	}
+
+	items := []TreeItem{{ID: root.Id, Name: root.Name, MimeType: root.MimeType}}
+	owned := map[string]bool{root.Id: root.OwnedByMe}
+	visited := map[string]bool{root.Id: true}
+
+	// Breadth-first walk; items grows while it is iterated.
+	for i := 0; i < len(items); i++ {
+		folder := items[i]
+		if folder.MimeType != folderMimeType {
+			continue
+		}
+
+		pageToken := ""
+		for {
+			call := dc.service.Files.List().
+				Context(ctx).
+				Q(fmt.Sprintf("'%s' in parents and trashed = false", escapeQuery(folder.ID))).
+				PageSize(MaxPageSize).
+				Fields("nextPageToken, files(id, name, mimeType, ownedByMe)")
+
+			if pageToken != "" {
+				call = call.PageToken(pageToken)
+			}
+
+			r, err := call.Do()
+			if err != nil {
+				return nil, fmt.Errorf("unable to list folder %s: %w", folder.ID, err)
+			}
+
+			for _, f := range r.Files {
+				// Items with several parents inside the subtree are planned once.
+				if visited[f.Id] {
+					continue
+				}
+				visited[f.Id] = true
+				owned[f.Id] = f.OwnedByMe
+				items = append(items, TreeItem{
+					ID:       f.Id,
+					Name:     f.Name,
+					MimeType: f.MimeType,
+					ParentID: folder.ID,
+					Depth:    folder.Depth + 1,
+				})
+			}
+
+			pageToken = r.NextPageToken
+			if pageToken == "" {
+				break
+			}
+		}
+	}
+
+	// Deepest first; the stable sort keeps siblings in listing order.
+	slices.SortStableFunc(items, func(a, b TreeItem) int {
+		return cmp.Compare(b.Depth, a.Depth)
+	})
+
+	// Children precede their folder, so skips propagate upwards in one pass.
+	blocked := make(map[string]bool)
+	for i := range items {
+		item := &items[i]
+		switch {
+		case !owned[item.ID]:
+			item.Skipped, item.SkipReason = true, "not owned by the caller"
+		case blocked[item.ID]:
+			item.Skipped, item.SkipReason = true, "contains skipped items"
+		}
+		if item.Skipped && item.ParentID != "" {
+			blocked[item.ParentID] = true
+		}
+	}
+
+	plan := &TreePlan{RootID: root.Id, Items: items}
+	plan.ConfirmToken = planToken(plan)
+	return plan, nil
+}
+
+// planToken derives a short confirmation token from the actionable item IDs.
+func planToken(p *TreePlan) string {
+	h := sha256.New()
+	h.Write([]byte(p.RootID))
+	for _, item := range p.Actionable() {
+		h.Write([]byte{0})
+		h.Write([]byte(item.ID))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// DeleteTree permanently deletes a file or folder and everything below it
+// that the caller owns. The subtree is enumerated first (see PlanTree) and
+// deleted bottom-up; folders containing skipped or failed items are kept.
+// WARNING: This action is irreversible.
+//
+// The operation runs only if opts.ConfirmToken matches the plan, or the plan
+// acts on at most opts.MaxItems items. With opts.DryRun, the plan is returned
+// and nothing is deleted.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - rootID: ID of the file or folder at the root of the subtree
+//   - opts: Dry-run and confirmation options
+//
+// Returns:
+//   - *TreePlan: The plan that was (or would be) executed
+//   - []ItemResult: One result per actionable item, in deletion order; nil for dry runs
+//   - error: ErrNotConfirmed if the operation was not confirmed, or any error encountered while planning
+//
+// Example:
+//
+//	plan, _, err := client.DeleteTree(ctx, folderID, gdrive.TreeOptions{DryRun: true})
+//	// Review plan.Items, then confirm with the token
+//	_, results, err := client.DeleteTree(ctx, folderID, gdrive.TreeOptions{ConfirmToken: plan.ConfirmToken})
+func (dc *DriveClient) DeleteTree(ctx context.Context, rootID string, opts TreeOptions) (*TreePlan, []ItemResult, error) {
+	plan, err := dc.confirmedPlan(ctx, rootID, opts)
+	if err != nil || opts.DryRun {
+		return plan, nil, err
+	}
+
+	actionable := plan.Actionable()
+	results := make([]ItemResult, 0, len(actionable))
+	blocked := make(map[string]bool)
+
+	for _, item := range actionable {
+		err := errors.New("skipped: contains items that could not be deleted")
+		if !blocked[item.ID] {
+			err = dc.DeleteFile(ctx, item.ID)
+		}
+		if err != nil && item.ParentID != "" {
+			blocked[item.ParentID] = true
+		}
+		results = append(results, ItemResult{FileID: item.ID, Err: err})
+	}
+
+	return plan, results, nil
+}
+
+// TrashTree moves a file or folder and everything below it that the caller
+// owns to the trash. It uses the same plan and confirmation rules as
+// DeleteTree, but only trashes the topmost actionable items: trashing a folder
+// also trashes its content, so the subtree can be restored in one step.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - rootID: ID of the file or folder at the root of the subtree
+//   - opts: Dry-run and confirmation options
+//
+// Returns:
+//   - *TreePlan: The plan that was (or would be) executed
+//   - []ItemResult: One result per trashed item; nil for dry runs
+//   - error: ErrNotConfirmed if the operation was not confirmed, or any error encountered while planning
+//
+// Example:
+//
+//	// Allow small subtrees without a separate review step
+//	plan, results, err := client.TrashTree(ctx, folderID, gdrive.TreeOptions{MaxItems: 50})
+//	if errors.Is(err, gdrive.ErrNotConfirmed) {
+//	    log.Printf("refusing to trash %d items", len(plan.Actionable()))
+//	}
+func (dc *DriveClient) TrashTree(ctx context.Context, rootID string, opts TreeOptions) (*TreePlan, []ItemResult, error) {
+	plan, err := dc.confirmedPlan(ctx, rootID, opts)
+	if err != nil || opts.DryRun {
+		return plan, nil, err
+	}
+
+	actionable := make(map[string]bool)
+	for _, item := range plan.Actionable() {
+		actionable[item.ID] = true
+	}
+
+	var ids []string
+	for _, item := range plan.Actionable() {
+		if !actionable[item.ParentID] {
+			ids = append(ids, item.ID)
+		}
+	}
+	return plan, dc.TrashMany(ctx, ids), nil
+}
+
+// confirmedPlan plans the subtree and checks the confirmation in opts.
+// Dry runs are never rejected.
+func (dc *DriveClient) confirmedPlan(ctx context.Context, rootID string, opts TreeOptions) (*TreePlan, error) {
+	plan, err := dc.PlanTree(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	if opts.ConfirmToken != "" {
+		if opts.ConfirmToken != plan.ConfirmToken {
+			return plan, fmt.Errorf("%w: confirm token does not match the current plan (%s)", ErrNotConfirmed, plan.ConfirmToken)
+		}
+		return plan, nil
+	}
+
+	count := len(plan.Actionable())
+	if opts.MaxItems > 0 && count <= opts.MaxItems {
+		return plan, nil
+	}
+	return plan, fmt.Errorf("%w: %d items; pass ConfirmToken %s or a MaxItems of at least %d",
+		ErrNotConfirmed, count, plan.ConfirmToken, count)
+}
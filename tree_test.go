@@ -0,0 +1,99 @@
+package gdrive_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/abiiranathan/gdrive"
+	"google.golang.org/api/drive/v3"
+)
+
+func TestPlanTree(t *testing.T) {
+	srv, client := newClient(t)
+	root := srv.AddFolder("Project", "")
+	docs := srv.AddFolder("docs", root)
+	shared := srv.AddFolder("shared", root)
+	readme := srv.AddFile("readme.txt", docs, "text/plain", []byte("readme"))
+	foreign := srv.AddFile("foreign.txt", shared, "text/plain", []byte("theirs"))
+	srv.Update(foreign, func(f *drive.File) { f.OwnedByMe = false })
+
+	plan, err := client.PlanTree(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Items) != 5 {
+		t.Fatalf("%d items planned, want 5", len(plan.Items))
+	}
+	if last := plan.Items[len(plan.Items)-1]; last.ID != root {
+		t.Errorf("last item %s, want the root", last.Name)
+	}
+
+	// The foreign file and every folder above it are kept.
+	skipped := make(map[string]bool)
+	for _, item := range plan.Items {
+		skipped[item.ID] = item.Skipped
+	}
+	want := map[string]bool{root: true, docs: false, shared: true, readme: false, foreign: true}
+	for id, skip := range want {
+		if skipped[id] != skip {
+			t.Errorf("%s: skipped=%v, want %v", id, skipped[id], skip)
+		}
+	}
+	if len(plan.Actionable()) != 2 {
+		t.Errorf("%d actionable items, want 2", len(plan.Actionable()))
+	}
+}
+
+func TestDeleteTree(t *testing.T) {
+	srv, client := newClient(t)
+	ctx := context.Background()
+	root := srv.AddFolder("Project", "")
+	docs := srv.AddFolder("docs", root)
+	readme := srv.AddFile("readme.txt", docs, "text/plain", []byte("readme"))
+	notes := srv.AddFile("notes.txt", root, "text/plain", []byte("notes"))
+
+	_, _, err := client.DeleteTree(ctx, root, gdrive.TreeOptions{})
+	if !errors.Is(err, gdrive.ErrNotConfirmed) {
+		t.Fatalf("unconfirmed delete: %v, want ErrNotConfirmed", err)
+	}
+	if _, _, err := client.DeleteTree(ctx, root, gdrive.TreeOptions{MaxItems: 3}); !errors.Is(err, gdrive.ErrNotConfirmed) {
+		t.Fatalf("delete of 4 items with MaxItems 3: %v, want ErrNotConfirmed", err)
+	}
+
+	plan, results, err := client.DeleteTree(ctx, root, gdrive.TreeOptions{DryRun: true})
+	if err != nil || results != nil {
+		t.Fatalf("dry run: results %v, err %v", results, err)
+	}
+	if _, ok := srv.Content(readme); !ok {
+		t.Fatal("dry run deleted a file")
+	}
+
+	// A change to the subtree invalidates the token.
+	extra := srv.AddFile("extra.txt", docs, "text/plain", []byte("extra"))
+	if _, _, err := client.DeleteTree(ctx, root, gdrive.TreeOptions{ConfirmToken: plan.ConfirmToken}); !errors.Is(err, gdrive.ErrNotConfirmed) {
+		t.Fatalf("delete with a stale token: %v, want ErrNotConfirmed", err)
+	}
+
+	plan, err = client.PlanTree(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, results, err = client.DeleteTree(ctx, root, gdrive.TreeOptions{ConfirmToken: plan.ConfirmToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 5 {
+		t.Errorf("%d results, want 5", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.FileID, r.Err)
+		}
+	}
+	for _, id := range []string{root, docs, readme, notes, extra} {
+		if _, ok := srv.Content(id); ok {
+			t.Errorf("%s still exists", id)
+		}
+	}
+}
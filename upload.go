@@ -0,0 +1,581 @@
+package gdrive
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// ErrUnsupportedConversion is returned when an upload asks for a conversion
+// that Google Drive does not offer for the file's MIME type (see About.importFormats).
+var ErrUnsupportedConversion = errors.New("unsupported conversion")
+
+// UploadOptions configures UploadFileWithOptions.
+type UploadOptions struct {
+	// FileName is the display name in Google Drive. If empty, the basename of the
+	// local file is used, without its extension when the file is converted.
+	FileName string
+
+	// ParentFolderID is the folder to upload into. Empty uploads to "My Drive" root.
+	ParentFolderID string
+
+	// MimeType of the source content. If empty, it is detected with the client's MimeDetector.
+	MimeType string
+
+	// Convert imports the file as the default Google Workspace type for its MIME type,
+	// e.g. DOCX, ODT, RTF, TXT and HTML to Docs, XLSX, ODS and CSV to Sheets,
+	// PPTX and ODP to Slides.
+	Convert bool
+
+	// ConvertTo imports the file as a specific Google Workspace type. Implies Convert.
+	// The conversion must be listed in Drive's importFormats for the source MIME type.
+	ConvertTo WorkspaceType
+
+	// OCRLanguage is an ISO 639-1 language hint (e.g., "en") for OCR when
+	// images or PDFs are converted to Google Docs.
+	OCRLanguage string
+
+	// Properties are public custom properties set on the created file.
+	Properties map[string]string
+
+	// AppProperties are private custom properties set on the created file,
+	// visible only to the calling app.
+	AppProperties map[string]string
+
+	// SkipQuotaCheck disables the pre-flight check of the file size against the
+	// user's remaining storage and Drive's maximum upload size. The check is
+	// skipped automatically for folders in shared drives, when the quota or
+	// the parent folder cannot be read, and when disabled with SetQuotaCheck.
+	SkipQuotaCheck bool
+
+	// Thumbnail is a custom thumbnail image for files Drive cannot generate one
+	// for, such as proprietary formats. At most MaxCustomThumbnailBytes (2 MB);
+	// PNG, JPEG or GIF. Larger images are rejected before the upload starts.
+	Thumbnail []byte
+
+	// ThumbnailMimeType is the MIME type of Thumbnail. If empty, it is sniffed.
+	ThumbnailMimeType string
+}
+
+// UploadFileWithOptions uploads a local file to Google Drive with additional options,
+// such as converting Office and CSV files into native Google Workspace documents.
+//
+// Requested conversions are validated against the importFormats advertised by
+// Drive's About resource before any content is sent. The formats are fetched once
+// per client and cached.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - filePath: Path to the local file to upload
+//   - opts: Name, parent folder, MIME type and conversion options
+//
+// Returns:
+//   - *FileInfo: Metadata of the created file (FolderPath is not populated)
+//   - error: Any error encountered during upload. Wraps ErrUnsupportedConversion
+//     if the requested conversion is not available. A *QuotaError (matching
+//     ErrQuotaExceeded) if the file does not fit the remaining storage.
+//
+// Example:
+//
+//	// Open a CSV export as a Google Sheet
+//	info, err := client.UploadFileWithOptions(ctx, "/exports/sales.csv", gdrive.UploadOptions{
+//	    ConvertTo:      gdrive.WorkspaceSpreadsheet,
+//	    ParentFolderID: folderID,
+//	})
+//
+//	// OCR a scanned PDF into a Google Doc
+//	info, err := client.UploadFileWithOptions(ctx, "/scans/letter.pdf", gdrive.UploadOptions{
+//	    ConvertTo:   gdrive.WorkspaceDocument,
+//	    OCRLanguage: "en",
+//	})
+func (dc *DriveClient) UploadFileWithOptions(ctx context.Context, filePath string, opts UploadOptions) (*FileInfo, error) {
+	uploadedFile, err := dc.uploadFile(ctx, filePath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("File uploaded successfully: %s (ID: %s, MIME type: %s)\n",
+		uploadedFile.Name, uploadedFile.Id, uploadedFile.MimeType)
+
+	info := newFileInfo(uploadedFile)
+	return &info, nil
+}
+
+// uploadFile opens, inspects and uploads a local file according to opts.
+func (dc *DriveClient) uploadFile(ctx context.Context, filePath string, opts UploadOptions) (*drive.File, error) {
+	if filePath == "" {
+		return nil, errors.New("file path cannot be empty")
+	}
+	contentHints, err := contentHintsThumbnail(opts.Thumbnail, opts.ThumbnailMimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file: %w", err)
+	}
+	defer file.Close()
+
+	mimeType := opts.MimeType
+	if mimeType == "" {
+		buffer := make([]byte, SniffLen)
+		n, err := io.ReadFull(file, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("unable to read file for MIME detection: %w", err)
+		}
+		mimeType = dc.detectMimeType(filepath.Base(filePath), buffer[:n])
+
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("unable to reset file pointer: %w", err)
+		}
+	}
+
+	target, err := dc.conversionTarget(ctx, mimeType, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Converted files are stored as Workspace documents, which use no quota.
+	// The check is best-effort: if the parent folder cannot be read (e.g., with
+	// the drive.file scope), the upload goes ahead unchecked.
+	if target == "" && !opts.SkipQuotaCheck && !dc.skipQuotaCheck {
+		shared := false
+		stat, err := file.Stat()
+		if err == nil && opts.ParentFolderID != "" {
+			shared, err = dc.inSharedDrive(ctx, opts.ParentFolderID)
+		}
+		if err == nil && !shared {
+			if err := dc.checkQuota(ctx, stat.Size(), stat.Size()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fileName := opts.FileName
+	if fileName == "" {
+		fileName = filepath.Base(filePath)
+		if target != "" {
+			fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+		}
+	}
+
+	fileMeta := &drive.File{
+		Name:          fileName,
+		MimeType:      mimeType,
+		Properties:    opts.Properties,
+		AppProperties: opts.AppProperties,
+		ContentHints:  contentHints,
+	}
+	if target != "" {
+		fileMeta.MimeType = target
+	}
+	if opts.ParentFolderID != "" {
+		fileMeta.Parents = []string{opts.ParentFolderID}
+	}
+
+	call := dc.service.Files.Create(fileMeta).
+		Context(ctx).
+		Media(file, googleapi.ContentType(mimeType)).
+		Fields(fileInfoFields)
+	if opts.OCRLanguage != "" {
+		call = call.OcrLanguage(opts.OCRLanguage)
+	}
+
+	uploadedFile, err := call.Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to upload file: %w", err)
+	}
+	dc.invalidateMetadata(uploadedFile.Id, uploadedFile.Parents, false)
+	return uploadedFile, nil
+}
+
+// conversionTarget returns the Google Workspace MIME type a file of sourceMimeType
+// should be imported as, or an empty string if opts do not request a conversion.
+func (dc *DriveClient) conversionTarget(ctx context.Context, sourceMimeType string, opts UploadOptions) (string, error) {
+	if !opts.Convert && opts.ConvertTo == "" {
+		return "", nil
+	}
+
+	imports, _, err := dc.formatMaps(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	targets := imports[sourceMimeType]
+	if len(targets) == 0 {
+		return "", fmt.Errorf("%w: %s files cannot be imported into Google Workspace", ErrUnsupportedConversion, sourceMimeType)
+	}
+	if opts.ConvertTo == "" {
+		return targets[0], nil
+	}
+	if !slices.Contains(targets, string(opts.ConvertTo)) {
+		return "", fmt.Errorf("%w: %s files can only be imported as %s",
+			ErrUnsupportedConversion, sourceMimeType, strings.Join(targets, ", "))
+	}
+	return string(opts.ConvertTo), nil
+}
+
+// ErrPreconditionFailed is returned by UpdateFileContent when the file changed
+// since the caller last saw it (see UpdateContentOptions.IfHeadRevisionID).
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// UpdateContentOptions configures UpdateFileContent.
+type UpdateContentOptions struct {
+	// MimeType of the new content. If empty, it is detected from the file name
+	// and content with the client's MimeDetector.
+	MimeType string
+
+	// KeepRevisionForever marks the new revision so Drive never prunes it.
+	KeepRevisionForever bool
+
+	// IfHeadRevisionID only updates the file if its current head revision has this ID.
+	IfHeadRevisionID string
+
+	// IfMd5Checksum only updates the file if its current content has this MD5 checksum.
+	IfMd5Checksum string
+
+	// Name renames the file in the same call. Empty leaves the name unchanged.
+	Name string
+
+	// Description replaces the file description in the same call. Empty leaves it unchanged.
+	Description string
+
+	// SkipQuotaCheck disables the pre-flight check of the new content against
+	// the user's remaining storage and Drive's maximum upload size.
+	SkipQuotaCheck bool
+}
+
+// UpdateFileContent replaces the content of an existing file, adding a new revision.
+// Unlike UploadFile, the file keeps its ID, so share links, bookmarks and
+// permissions stay valid.
+//
+// The If* options guard against clobbering concurrent edits: the current head
+// revision and checksum are compared before uploading, and ErrPreconditionFailed
+// is returned on mismatch. Drive has no conditional update, so a write landing
+// between the check and the upload is not detected.
+//
+// When the size of reader is known (*os.File, or any reader with a Len method
+// such as *bytes.Reader), the growth of the file is checked against the user's
+// remaining storage before uploading, as with UploadFile. Files in shared drives
+// are not checked, and the update goes ahead if the quota cannot be read.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file to update
+//   - reader: Source of the new content
+//   - opts: MIME type, revision, precondition and metadata options
+//
+// Returns:
+//   - *FileInfo: Metadata after the update (FolderPath is not populated)
+//   - error: Any error encountered. Wraps ErrPreconditionFailed on a precondition mismatch,
+//     and is a *QuotaError (matching ErrQuotaExceeded) if the new content does not fit
+//
+// Example:
+//
+//	info, _ := client.GetFile(ctx, fileID)
+//	// ... edit ...
+//	updated, err := client.UpdateFileContent(ctx, fileID, bytes.NewReader(data), gdrive.UpdateContentOptions{
+//	    IfHeadRevisionID:    info.HeadRevisionID,
+//	    KeepRevisionForever: true,
+//	})
+//	if errors.Is(err, gdrive.ErrPreconditionFailed) {
+//	    // Someone else changed the file; reload and merge
+//	}
+func (dc *DriveClient) UpdateFileContent(ctx context.Context, fileID string, reader io.Reader, opts UpdateContentOptions) (*FileInfo, error) {
+	if fileID == "" {
+		return nil, errors.New("file ID cannot be empty")
+	}
+	if reader == nil {
+		return nil, errors.New("reader cannot be nil")
+	}
+
+	current, err := dc.service.Files.Get(fileID).
+		Context(ctx).
+		SupportsAllDrives(true).
+		Fields("name, size, driveId, headRevisionId, md5Checksum").
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get file metadata: %w", err)
+	}
+
+	if opts.IfHeadRevisionID != "" && opts.IfHeadRevisionID != current.HeadRevisionId {
+		return nil, fmt.Errorf("%w: head revision is %s, expected %s",
+			ErrPreconditionFailed, current.HeadRevisionId, opts.IfHeadRevisionID)
+	}
+	if opts.IfMd5Checksum != "" && !strings.EqualFold(opts.IfMd5Checksum, current.Md5Checksum) {
+		return nil, fmt.Errorf("%w: md5 checksum is %s, expected %s",
+			ErrPreconditionFailed, current.Md5Checksum, opts.IfMd5Checksum)
+	}
+
+	if !opts.SkipQuotaCheck && !dc.skipQuotaCheck && current.DriveId == "" {
+		if size, ok := contentLength(reader); ok {
+			if err := dc.checkQuota(ctx, size, size-current.Size); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	mimeType := opts.MimeType
+	if mimeType == "" {
+		name := opts.Name
+		if name == "" {
+			name = current.Name
+		}
+		br := bufio.NewReaderSize(reader, SniffLen)
+		head, err := br.Peek(SniffLen)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, fmt.Errorf("unable to read content for MIME detection: %w", err)
+		}
+		mimeType = dc.detectMimeType(name, head)
+		reader = br
+	}
+
+	patch := &drive.File{
+		Name:        opts.Name,
+		Description: opts.Description,
+	}
+
+	updated, err := dc.service.Files.Update(fileID, patch).
+		Context(ctx).
+		Media(reader, googleapi.ContentType(mimeType)).
+		KeepRevisionForever(opts.KeepRevisionForever).
+		Fields(fileInfoFields).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update file content: %w", err)
+	}
+	dc.invalidateMetadata(updated.Id, updated.Parents, false)
+
+	fmt.Printf("File content updated: %s (ID: %s, revision: %s)\n", updated.Name, updated.Id, updated.HeadRevisionId)
+
+	info := newFileInfo(updated)
+	return &info, nil
+}
+
+// contentLength returns the number of bytes left in r if it can be determined
+// without reading.
+func contentLength(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case *os.File:
+		stat, err := v.Stat()
+		if err != nil || !stat.Mode().IsRegular() {
+			return 0, false
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		return stat.Size() - offset, true
+	}
+	return 0, false
+}
+
+// UpdateFileContentFromFile replaces the content of an existing file with a local file.
+// It is a convenience wrapper around UpdateFileContent.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the file to update
+//   - filePath: Path to the local file with the new content
+//   - opts: MIME type, revision, precondition and metadata options
+//
+// Returns:
+//   - *FileInfo: Metadata after the update (FolderPath is not populated)
+//   - error: Any error encountered. Wraps ErrPreconditionFailed on a precondition mismatch,
+//     and is a *QuotaError (matching ErrQuotaExceeded) if the new content does not fit
+//
+// Example:
+//
+//	info, err := client.UpdateFileContentFromFile(ctx, fileID, "/reports/daily.pdf",
+//	    gdrive.UpdateContentOptions{})
+func (dc *DriveClient) UpdateFileContentFromFile(ctx context.Context, fileID, filePath string, opts UpdateContentOptions) (*FileInfo, error) {
+	if filePath == "" {
+		return nil, errors.New("file path cannot be empty")
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file: %w", err)
+	}
+	defer file.Close()
+
+	if opts.MimeType == "" {
+		buffer := make([]byte, SniffLen)
+		n, err := io.ReadFull(file, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("unable to read file for MIME detection: %w", err)
+		}
+		opts.MimeType = dc.detectMimeType(filepath.Base(filePath), buffer[:n])
+
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("unable to reset file pointer: %w", err)
+		}
+	}
+
+	return dc.UpdateFileContent(ctx, fileID, file, opts)
+}
+
+// ErrDuplicateFiles is returned by UploadOrReplace when several files with the
+// same name already exist in the folder and the policy is DuplicateError.
+var ErrDuplicateFiles = errors.New("multiple files with the same name")
+
+// DuplicatePolicy decides what UploadOrReplace does when several non-trashed
+// files with the target name already exist in the folder.
+type DuplicatePolicy int
+
+const (
+	// DuplicateError fails with ErrDuplicateFiles without changing anything.
+	DuplicateError DuplicatePolicy = iota
+	// DuplicateUpdateNewest updates the most recently modified match.
+	DuplicateUpdateNewest
+	// DuplicateUpdateOldest updates the least recently modified match.
+	DuplicateUpdateOldest
+	// DuplicateUpdateNewestTrashOthers updates the most recently modified match
+	// and moves the other matches to the trash.
+	DuplicateUpdateNewestTrashOthers
+)
+
+// UpsertOptions configures UploadOrReplace.
+type UpsertOptions struct {
+	// OnDuplicates chooses how to handle several existing matches. Defaults to DuplicateError.
+	OnDuplicates DuplicatePolicy
+
+	// MimeType of the content. If empty, it is detected with the client's MimeDetector.
+	MimeType string
+
+	// KeepRevisionForever marks the new revision of a replaced file so Drive never prunes it.
+	KeepRevisionForever bool
+
+	// SkipQuotaCheck disables the pre-flight storage check of both the upload
+	// of a new file and the update of an existing one.
+	SkipQuotaCheck bool
+}
+
+// UploadOrReplace uploads a local file under an exact name in a folder, replacing
+// the content of an existing non-trashed file with that name instead of creating
+// a duplicate. A replaced file keeps its ID and gets a new revision; if no file
+// matches, a new one is created as with UploadFile.
+//
+// The content is uploaded without conversion, so Google Workspace documents with
+// the same name are ignored rather than overwritten with binary content. Both the
+// upload and the replacement are checked against the storage quota first.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - filePath: Path to the local file to upload
+//   - fileName: Exact display name to look up and upload as. If empty, uses the basename of filePath
+//   - parentFolderID: ID of the parent folder. Empty string uses the "My Drive" root
+//   - opts: Duplicate handling, MIME type and revision options
+//
+// Returns:
+//   - *FileInfo: Metadata of the created or updated file (FolderPath is not populated)
+//   - bool: true if a new file was created, false if an existing file was updated
+//   - error: Any error encountered. Wraps ErrDuplicateFiles under DuplicateError
+//
+// Example:
+//
+//	// Re-publish the daily report without piling up copies
+//	info, created, err := client.UploadOrReplace(ctx, "/out/report.pdf", "report.pdf", folderID,
+//	    gdrive.UpsertOptions{OnDuplicates: gdrive.DuplicateUpdateNewestTrashOthers})
+func (dc *DriveClient) UploadOrReplace(ctx context.Context, filePath, fileName, parentFolderID string, opts UpsertOptions) (*FileInfo, bool, error) {
+	if filePath == "" {
+		return nil, false, errors.New("file path cannot be empty")
+	}
+	if fileName == "" {
+		fileName = filepath.Base(filePath)
+	}
+
+	parent := parentFolderID
+	if parent == "" {
+		parent = "root"
+	}
+	// UploadOrReplace never converts, so Workspace documents (including folders
+	// and shortcuts) with the same name are not candidates for replacement.
+	query := fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false and not mimeType contains 'application/vnd.google-apps.'",
+		escapeQuery(fileName), escapeQuery(parent))
+
+	var matches []*drive.File
+	var pageToken string
+	for {
+		call := dc.service.Files.List().
+			Context(ctx).
+			Q(query).
+			OrderBy("modifiedTime desc").
+			PageSize(MaxPageSize).
+			Fields("nextPageToken, files(id, name, modifiedTime)")
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		r, err := call.Do()
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to look up existing files: %w", err)
+		}
+		matches = append(matches, r.Files...)
+
+		pageToken = r.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	if len(matches) == 0 {
+		info, err := dc.UploadFileWithOptions(ctx, filePath, UploadOptions{
+			FileName:       fileName,
+			ParentFolderID: parentFolderID,
+			MimeType:       opts.MimeType,
+			SkipQuotaCheck: opts.SkipQuotaCheck,
+		})
+		return info, err == nil, err
+	}
+
+	target := matches[0]
+	if len(matches) > 1 {
+		switch opts.OnDuplicates {
+		case DuplicateUpdateNewest, DuplicateUpdateNewestTrashOthers:
+			// Already ordered newest first
+		case DuplicateUpdateOldest:
+			target = matches[len(matches)-1]
+		default:
+			return nil, false, fmt.Errorf("%w: %d files named %q in folder %s",
+				ErrDuplicateFiles, len(matches), fileName, parent)
+		}
+	}
+
+	info, err := dc.UpdateFileContentFromFile(ctx, target.Id, filePath, UpdateContentOptions{
+		MimeType:            opts.MimeType,
+		KeepRevisionForever: opts.KeepRevisionForever,
+		SkipQuotaCheck:      opts.SkipQuotaCheck,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if opts.OnDuplicates == DuplicateUpdateNewestTrashOthers {
+		for _, other := range matches[1:] {
+			if err := dc.TrashFile(ctx, other.Id); err != nil {
+				return info, false, err
+			}
+		}
+	}
+
+	return info, false, nil
+}
+
+// escapeQuery escapes a string for use inside a single-quoted
+// Drive search query literal.
+func escapeQuery(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
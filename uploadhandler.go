@@ -0,0 +1,343 @@
+package gdrive
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// errUploadTooLarge is returned by limitedReader once its limit is exceeded.
+var errUploadTooLarge = errors.New("upload exceeds size limit")
+
+// UploadHandlerOptions configures the handler returned by UploadHandler.
+type UploadHandlerOptions struct {
+	// FieldName restricts uploads to multipart file parts with this form name.
+	// Empty accepts files from any field.
+	FieldName string
+
+	// FolderField is the query parameter or form field holding the target folder ID.
+	// Defaults to "folder_id". A form field must precede the file parts in the request body.
+	FolderField string
+
+	// DefaultFolderID is used when the request does not specify a folder.
+	// Empty uploads to the "My Drive" root.
+	DefaultFolderID string
+
+	// FolderAllowed authorizes the target folder of a request.
+	// If nil, any folder accessible to the DriveClient is allowed.
+	FolderAllowed func(r *http.Request, folderID string) bool
+
+	// MaxFileSize is the maximum size of a single file in bytes. Zero means unlimited.
+	MaxFileSize int64
+
+	// MaxTotalSize is the maximum size of the request body in bytes. Zero means unlimited.
+	MaxTotalSize int64
+
+	// AllowedMimeTypes lists accepted MIME types. Entries may use a wildcard
+	// subtype such as "image/*". Both the declared Content-Type of a part and
+	// the type sniffed from its content must be listed; content that is only
+	// recognized as text, XML or ZIP is checked by its detected type instead,
+	// and unrecognized binary content needs "application/octet-stream".
+	// Empty allows all types.
+	AllowedMimeTypes []string
+
+	// MimeDetector detects the type of parts without a specific Content-Type.
+	// If nil, DefaultMimeDetector is used; the detector set on the client with
+	// SetMimeDetector is not consulted, so pass it here to share it.
+	MimeDetector MimeDetector
+}
+
+// UploadResponse is the JSON body written by UploadHandler.
+// On failure, Files lists the uploads that completed before the error.
+type UploadResponse struct {
+	Files []UploadedFile `json:"files"`
+	Error string         `json:"error,omitempty"`
+}
+
+// UploadedFile describes a file created by UploadHandler in its JSON response.
+type UploadedFile struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	MimeType     string    `json:"mimeType"`
+	Size         int64     `json:"size"`
+	WebViewLink  string    `json:"webViewLink,omitempty"`
+	Parents      []string  `json:"parents,omitempty"`
+	Md5Checksum  string    `json:"md5Checksum,omitempty"`
+	ModifiedTime time.Time `json:"modifiedTime"`
+}
+
+// newUploadedFile converts FileInfo to the response representation.
+func newUploadedFile(info FileInfo) UploadedFile {
+	return UploadedFile{
+		ID:           info.ID,
+		Name:         info.Name,
+		MimeType:     info.MimeType,
+		Size:         info.Size,
+		WebViewLink:  info.WebViewLink,
+		Parents:      info.Parents,
+		Md5Checksum:  info.Md5Checksum,
+		ModifiedTime: info.ModifiedTime,
+	}
+}
+
+// UploadHandler returns an http.Handler that uploads multipart/form-data files to Google Drive.
+//
+// Parts are streamed directly into Drive as they are read from the request body;
+// nothing is buffered to disk. The MIME type of each file is taken from the part's
+// Content-Type header, or detected by opts.MimeDetector when the header is missing
+// or generic. Before the upload starts, both the declared type and the type
+// sniffed from the leading bytes must match AllowedMimeTypes, so a client cannot
+// pass the allowlist by declaring an allowed type or file name for other content.
+// Size limits are enforced while streaming, so oversized files are rejected with
+// 413 Request Entity Too Large without being created in Drive.
+//
+// Files are created with UploadFileFromReaderWithInfo, which also returns their
+// metadata for the response. The response is a JSON encoded UploadResponse with
+// status 201 Created. Only POST is allowed.
+//
+// Parameters:
+//   - client: Client used for uploads, usually a *DriveClient
+//   - opts: Field names, size limits and MIME type allowlist
+//
+// Returns:
+//   - http.Handler: Handler accepting multipart uploads
+//
+// Example:
+//
+//	mux.Handle("POST /upload", gdrive.UploadHandler(client, gdrive.UploadHandlerOptions{
+//	    FieldName:        "file",
+//	    MaxFileSize:      100 << 20, // 100 MB
+//	    MaxTotalSize:     500 << 20, // 500 MB
+//	    AllowedMimeTypes: []string{"image/*", "application/pdf"},
+//	}))
+//	// curl -F folder_id=1aBc2DeF -F file=@report.pdf http://localhost:8080/upload
+func UploadHandler(client UploadHandlerClient, opts UploadHandlerOptions) http.Handler {
+	if opts.FolderField == "" {
+		opts.FolderField = "folder_id"
+	}
+	if opts.MimeDetector == nil {
+		opts.MimeDetector = DefaultMimeDetector{}
+	}
+	return &uploadHandler{client: client, opts: opts}
+}
+
+type uploadHandler struct {
+	client UploadHandlerClient
+	opts   UploadHandlerOptions
+}
+
+func (h *uploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeUploadResponse(w, http.StatusMethodNotAllowed, nil, "method not allowed")
+		return
+	}
+
+	if h.opts.MaxTotalSize > 0 && r.ContentLength > h.opts.MaxTotalSize {
+		writeUploadResponse(w, http.StatusRequestEntityTooLarge, nil, "request body too large")
+		return
+	}
+
+	body := newLimitedReader(r.Body, h.opts.MaxTotalSize)
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, r.Body}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeUploadResponse(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	folderID := r.URL.Query().Get(h.opts.FolderField)
+	files := make([]FileInfo, 0, 1)
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if body.exceeded {
+				writeUploadResponse(w, http.StatusRequestEntityTooLarge, files, "request body too large")
+			} else {
+				writeUploadResponse(w, http.StatusBadRequest, files, err.Error())
+			}
+			return
+		}
+
+		if part.FileName() == "" {
+			if part.FormName() == h.opts.FolderField && folderID == "" {
+				value, err := io.ReadAll(io.LimitReader(part, 1024))
+				if err != nil {
+					writeUploadResponse(w, http.StatusBadRequest, files, err.Error())
+					return
+				}
+				folderID = strings.TrimSpace(string(value))
+			}
+			part.Close()
+			continue
+		}
+
+		if h.opts.FieldName != "" && part.FormName() != h.opts.FieldName {
+			part.Close()
+			continue
+		}
+
+		if folderID == "" {
+			folderID = h.opts.DefaultFolderID
+		}
+		if h.opts.FolderAllowed != nil && !h.opts.FolderAllowed(r, folderID) {
+			writeUploadResponse(w, http.StatusForbidden, files, "target folder not allowed")
+			return
+		}
+
+		status, info, err := h.uploadPart(r, part, folderID, body)
+		part.Close()
+		if err != nil {
+			writeUploadResponse(w, status, files, err.Error())
+			return
+		}
+		files = append(files, info)
+	}
+
+	if len(files) == 0 {
+		writeUploadResponse(w, http.StatusBadRequest, files, "no files in request")
+		return
+	}
+	writeUploadResponse(w, http.StatusCreated, files, "")
+}
+
+// uploadPart streams a single multipart file part into Drive.
+// On failure it returns the HTTP status code that best describes the error.
+func (h *uploadHandler) uploadPart(r *http.Request, part *multipart.Part, folderID string, body *limitedReader) (int, FileInfo, error) {
+	content := newLimitedReader(part, h.opts.MaxFileSize)
+	br := bufio.NewReaderSize(content, SniffLen)
+
+	head, err := br.Peek(SniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		if content.exceeded || body.exceeded {
+			return http.StatusRequestEntityTooLarge, FileInfo{}, fmt.Errorf("%s: %w", part.FileName(), errUploadTooLarge)
+		}
+		return http.StatusBadRequest, FileInfo{}, err
+	}
+
+	fileName := path.Base(strings.ReplaceAll(part.FileName(), "\\", "/"))
+	detected := h.detectMimeType(fileName, head)
+	mimeType := detected
+	if declared, _, err := mime.ParseMediaType(part.Header.Get("Content-Type")); err == nil && declared != "application/octet-stream" {
+		mimeType = declared
+	}
+	// The declared type and the file name are chosen by the client,
+	// so the content must pass too.
+	if !mimeTypeAllowed(mimeType, h.opts.AllowedMimeTypes) {
+		return http.StatusUnsupportedMediaType, FileInfo{}, fmt.Errorf("%s: MIME type %s not allowed", fileName, mimeType)
+	}
+	if sniffed := h.detectMimeType("", head); !h.contentAllowed(sniffed, detected, len(head) > 0) {
+		return http.StatusUnsupportedMediaType, FileInfo{}, fmt.Errorf("%s: content of type %s not allowed", fileName, sniffed)
+	}
+
+	info, err := h.client.UploadFileFromReaderWithInfo(r.Context(), br, fileName, mimeType, folderID)
+	if content.exceeded || body.exceeded {
+		return http.StatusRequestEntityTooLarge, FileInfo{}, fmt.Errorf("%s: %w", fileName, errUploadTooLarge)
+	}
+	if err != nil {
+		return httpStatusFromError(err), FileInfo{}, fmt.Errorf("unable to upload %s: %w", fileName, err)
+	}
+	return http.StatusCreated, *info, nil
+}
+
+// detectMimeType returns the type detected by the MimeDetector from the file
+// name and leading content, falling back to DefaultMimeDetector.
+func (h *uploadHandler) detectMimeType(fileName string, head []byte) string {
+	if t := h.opts.MimeDetector.DetectMimeType(fileName, head); t != "" {
+		return t
+	}
+	return DefaultMimeDetector{}.DetectMimeType(fileName, head)
+}
+
+// contentAllowed reports whether content sniffed as sniffed, without looking
+// at the file name, passes the allowlist. Vague results such as text/plain or
+// application/zip cannot tell formats apart, so for those the type detected
+// with the file name (detected) is checked instead; unrecognized binary content
+// must be allowed as application/octet-stream. Empty content always passes.
+func (h *uploadHandler) contentAllowed(sniffed, detected string, hasContent bool) bool {
+	if !hasContent || mimeTypeAllowed(sniffed, h.opts.AllowedMimeTypes) {
+		return true
+	}
+	return genericSniffedTypes[sniffed] && sniffed != "application/octet-stream" &&
+		mimeTypeAllowed(detected, h.opts.AllowedMimeTypes)
+}
+
+// mimeTypeAllowed reports whether mimeType matches an entry of allowed.
+// Entries ending in "/*" match any subtype. An empty list allows everything.
+func mimeTypeAllowed(mimeType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, mimeType) {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(mimeType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedReader reads from r until more than remaining bytes have been read,
+// after which it fails with errUploadTooLarge and records the fact in exceeded.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	unlimited bool
+	exceeded  bool
+}
+
+// newLimitedReader returns a limitedReader allowing limit bytes.
+// A limit of zero or less disables the check.
+func newLimitedReader(r io.Reader, limit int64) *limitedReader {
+	return &limitedReader{r: r, remaining: limit, unlimited: limit <= 0}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, errUploadTooLarge
+	}
+	if l.unlimited {
+		return l.r.Read(p)
+	}
+
+	// Read one byte past the limit to detect oversized input.
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.remaining {
+		n, l.remaining, l.exceeded = int(l.remaining), 0, true
+		return n, errUploadTooLarge
+	}
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// writeUploadResponse writes an UploadResponse as JSON with the given status code.
+func writeUploadResponse(w http.ResponseWriter, status int, files []FileInfo, errMsg string) {
+	resp := UploadResponse{Files: make([]UploadedFile, 0, len(files)), Error: errMsg}
+	for _, info := range files {
+		resp.Files = append(resp.Files, newUploadedFile(info))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,139 @@
+package gdrive_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/abiiranathan/gdrive"
+)
+
+// filePart is a file in a multipart upload request.
+type filePart struct {
+	name        string
+	contentType string // empty omits the header
+	content     []byte
+}
+
+// upload posts parts to handler and returns the status and decoded response.
+func upload(t *testing.T, handler http.Handler, folderID string, parts ...filePart) (int, gdrive.UploadResponse) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if folderID != "" {
+		mw.WriteField("folder_id", folderID)
+	}
+	for _, p := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="file"; filename="`+p.name+`"`)
+		if p.contentType != "" {
+			header.Set("Content-Type", p.contentType)
+		}
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(p.content)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp gdrive.UploadResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+var (
+	pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	elfHeader = []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+)
+
+func TestUploadHandler(t *testing.T) {
+	srv, client := newClient(t)
+	folder := srv.AddFolder("Uploads", "")
+	handler := gdrive.UploadHandler(client, gdrive.UploadHandlerOptions{FieldName: "file"})
+
+	srv.ResetRequests()
+	status, resp := upload(t, handler, folder,
+		filePart{name: "a.txt", contentType: "text/plain", content: []byte("alpha")},
+		filePart{name: "b.png", content: pngHeader},
+	)
+	if status != http.StatusCreated {
+		t.Fatalf("status %d: %s", status, resp.Error)
+	}
+	if len(resp.Files) != 2 {
+		t.Fatalf("%d files in the response, want 2", len(resp.Files))
+	}
+	if f := resp.Files[1]; f.Name != "b.png" || f.MimeType != "image/png" || f.Parents[0] != folder {
+		t.Errorf("second file %+v, want b.png (image/png) in %s", f, folder)
+	}
+	if content, _ := srv.Content(resp.Files[0].ID); string(content) != "alpha" {
+		t.Errorf("content %q, want alpha", content)
+	}
+	// The response is built from the upload responses; nothing is read back.
+	if got := len(srv.Requests()); got != 2 {
+		t.Errorf("%d requests for 2 uploads, want 2: %q", got, srv.Requests())
+	}
+}
+
+func TestUploadHandlerAllowlist(t *testing.T) {
+	srv, client := newClient(t)
+	handler := gdrive.UploadHandler(client, gdrive.UploadHandlerOptions{
+		AllowedMimeTypes: []string{"image/*", "text/csv"},
+	})
+
+	tests := []struct {
+		part   filePart
+		status int
+	}{
+		{filePart{name: "a.png", contentType: "image/png", content: pngHeader}, http.StatusCreated},
+		{filePart{name: "a.png", content: pngHeader}, http.StatusCreated},
+		{filePart{name: "a.txt", contentType: "text/plain", content: []byte("alpha")}, http.StatusUnsupportedMediaType},
+		// The declared type is allowed, the content is not.
+		{filePart{name: "a.png", contentType: "image/png", content: elfHeader}, http.StatusUnsupportedMediaType},
+		{filePart{name: "a.png", content: elfHeader}, http.StatusUnsupportedMediaType},
+		// Text content is checked by the type detected from the name.
+		{filePart{name: "a.csv", content: []byte("a,b\n1,2\n")}, http.StatusCreated},
+		{filePart{name: "a.csv", contentType: "text/csv", content: elfHeader}, http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		srv.ResetRequests()
+		status, resp := upload(t, handler, "", tt.part)
+		if status != tt.status {
+			t.Errorf("%s (%q): status %d, want %d: %s", tt.part.name, tt.part.contentType, status, tt.status, resp.Error)
+		}
+		if tt.status != http.StatusCreated && len(srv.Requests()) != 0 {
+			t.Errorf("%s (%q): rejected file was sent: %q", tt.part.name, tt.part.contentType, srv.Requests())
+		}
+	}
+}
+
+func TestUploadHandlerLimits(t *testing.T) {
+	srv, client := newClient(t)
+	handler := gdrive.UploadHandler(client, gdrive.UploadHandlerOptions{MaxFileSize: 10})
+
+	status, resp := upload(t, handler, "",
+		filePart{name: "small.txt", contentType: "text/plain", content: []byte("ok")},
+		filePart{name: "big.txt", contentType: "text/plain", content: bytes.Repeat([]byte("x"), 100)},
+	)
+	if status != http.StatusRequestEntityTooLarge {
+		t.Errorf("status %d, want 413", status)
+	}
+	// The first file completed before the error and is reported.
+	if len(resp.Files) != 1 || resp.Files[0].Name != "small.txt" {
+		t.Errorf("files %+v, want small.txt", resp.Files)
+	}
+	if got := countRequests(srv, "POST", "/upload/drive/v3/files"); got > 2 {
+		t.Errorf("%d uploads started, want at most 2", got)
+	}
+}
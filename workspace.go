@@ -0,0 +1,210 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// WorkspaceType is the MIME type of a native Google Workspace document.
+type WorkspaceType string
+
+// Google Workspace document types.
+const (
+	WorkspaceDocument     WorkspaceType = "application/vnd.google-apps.document"     // Google Docs
+	WorkspaceSpreadsheet  WorkspaceType = "application/vnd.google-apps.spreadsheet"  // Google Sheets
+	WorkspacePresentation WorkspaceType = "application/vnd.google-apps.presentation" // Google Slides
+	WorkspaceDrawing      WorkspaceType = "application/vnd.google-apps.drawing"      // Google Drawings
+	WorkspaceScript       WorkspaceType = "application/vnd.google-apps.script"       // Google Apps Script
+	WorkspaceJam          WorkspaceType = "application/vnd.google-apps.jam"          // Google Jamboard
+	WorkspaceForm         WorkspaceType = "application/vnd.google-apps.form"         // Google Forms
+)
+
+// formatMaps returns the importFormats and exportFormats maps of the About resource.
+// They describe server capabilities that do not change for the lifetime of a
+// client, so they are fetched once and cached. Failed fetches are not cached.
+func (dc *DriveClient) formatMaps(ctx context.Context) (imports, exports map[string][]string, err error) {
+	dc.formatsMu.Lock()
+	defer dc.formatsMu.Unlock()
+
+	if dc.importFormats != nil {
+		return dc.importFormats, dc.exportFormats, nil
+	}
+
+	about, err := dc.service.About.Get().
+		Context(ctx).
+		Fields("importFormats, exportFormats").
+		Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get supported formats: %w", err)
+	}
+
+	dc.importFormats = about.ImportFormats
+	dc.exportFormats = about.ExportFormats
+	if dc.importFormats == nil {
+		dc.importFormats = map[string][]string{}
+	}
+	return dc.importFormats, dc.exportFormats, nil
+}
+
+// ErrNotExportable is returned when a Google Workspace document type has no
+// export formats, such as Google Forms.
+var ErrNotExportable = errors.New("document cannot be exported")
+
+// defaultExportFormats is the built-in table of export formats per Workspace type.
+// The first entry of each list is the default format for that type.
+// It is used when Drive's about.exportFormats is unavailable or lacks a type.
+// It is never modified; callers outside the package get a copy from DefaultExportFormats.
+var defaultExportFormats = map[WorkspaceType][]ExportFormat{
+	WorkspaceDocument: {
+		ExportFormatDOCX, ExportFormatPDF, ExportFormatODT, ExportFormatRTF, ExportFormatTXT,
+		ExportFormatHTML, ExportFormatEPUB, ExportFormatMD, ExportFormatZIP,
+	},
+	WorkspaceSpreadsheet: {
+		ExportFormatXLSX, ExportFormatPDF, ExportFormatODS, ExportFormatCSV, ExportFormatTSV, ExportFormatZIP,
+	},
+	WorkspacePresentation: {
+		ExportFormatPPTX, ExportFormatPDF, ExportFormatODP, ExportFormatTXT,
+		ExportFormatJPEG, ExportFormatPNG, ExportFormatSVG,
+	},
+	WorkspaceDrawing: {ExportFormatPDF, ExportFormatPNG, ExportFormatJPEG, ExportFormatSVG},
+	WorkspaceScript:  {ExportFormatJSON},
+	WorkspaceJam:     {ExportFormatPDF},
+	WorkspaceForm:    nil,
+}
+
+// DefaultExportFormats returns a copy of the built-in table of export formats
+// per Workspace type. The first entry of each list is the default format for
+// that type. The table is used when Drive's about.exportFormats is unavailable
+// or lacks a type. Modifying the returned map does not affect the package.
+//
+// Returns:
+//   - map[WorkspaceType][]ExportFormat: Export formats per Workspace type, default first
+//
+// Example:
+//
+//	for t, formats := range gdrive.DefaultExportFormats() {
+//	    fmt.Printf("%s: %v\n", t, formats)
+//	}
+func DefaultExportFormats() map[WorkspaceType][]ExportFormat {
+	formats := make(map[WorkspaceType][]ExportFormat, len(defaultExportFormats))
+	for t, list := range defaultExportFormats {
+		formats[t] = slices.Clone(list)
+	}
+	return formats
+}
+
+// DefaultExportFormat returns the default export format of a Workspace type
+// from DefaultExportFormats. Returns false for types without export formats.
+func DefaultExportFormat(t WorkspaceType) (ExportFormat, bool) {
+	formats := defaultExportFormats[t]
+	if len(formats) == 0 {
+		return "", false
+	}
+	return formats[0], true
+}
+
+// SupportedExportFormats returns the formats a Workspace type can be exported to.
+// The list comes from Drive's about.exportFormats (fetched once per client),
+// ordered so that the default format from DefaultExportFormats comes first.
+// If the About resource cannot be fetched or does not list the type,
+// DefaultExportFormats is used instead.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - t: Workspace document type (e.g., gdrive.WorkspaceSpreadsheet)
+//
+// Returns:
+//   - []ExportFormat: Supported formats, default first. Empty if the type cannot be exported
+//
+// Example:
+//
+//	formats := client.SupportedExportFormats(ctx, gdrive.WorkspaceSpreadsheet)
+//	// [xlsx pdf ods csv tsv zip]
+func (dc *DriveClient) SupportedExportFormats(ctx context.Context, t WorkspaceType) []ExportFormat {
+	fallback := defaultExportFormats[t]
+
+	_, exports, err := dc.formatMaps(ctx)
+	if err != nil || len(exports[string(t)]) == 0 {
+		return slices.Clone(fallback)
+	}
+
+	formats := make([]ExportFormat, 0, len(exports[string(t)]))
+	for _, f := range exports[string(t)] {
+		formats = append(formats, ExportFormat(f))
+	}
+
+	if def, ok := DefaultExportFormat(t); ok {
+		if i := slices.Index(formats, def); i > 0 {
+			formats = slices.Insert(slices.Delete(formats, i, i+1), 0, def)
+		}
+	}
+	return formats
+}
+
+// negotiateExportFormat picks the first preference supported for t, falling
+// back to the default format and then to any supported format.
+func (dc *DriveClient) negotiateExportFormat(ctx context.Context, t WorkspaceType, preferences []ExportFormat) (ExportFormat, error) {
+	supported := dc.SupportedExportFormats(ctx, t)
+	if len(supported) == 0 {
+		return "", fmt.Errorf("%w: %s has no export formats", ErrNotExportable, t)
+	}
+
+	for _, p := range preferences {
+		if slices.Contains(supported, p) {
+			return p, nil
+		}
+	}
+	return supported[0], nil
+}
+
+// ExportAuto exports a Google Workspace document in the best available format.
+// The first of the given preferences supported by the document's type is used.
+// If none applies, or no preferences are given, the type's default format is used
+// (DOCX for Docs, XLSX for Sheets, PPTX for Slides, PDF for Drawings).
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - fileID: ID of the Google Workspace document
+//   - w: Destination writer for the exported content
+//   - preferences: Export formats in order of preference
+//
+// Returns:
+//   - ExportFormat: The format the document was exported to
+//   - string: File extension of that format without the dot (e.g., "pdf")
+//   - error: Any error encountered during export. Wraps ErrNotExportable if the
+//     document type has no export formats (e.g., Google Forms)
+//
+// Example:
+//
+//	// PDF for Docs and Slides, CSV for Sheets
+//	var buf bytes.Buffer
+//	format, ext, err := client.ExportAuto(ctx, fileID, &buf, gdrive.ExportFormatPDF, gdrive.ExportFormatCSV)
+//	os.WriteFile("export."+ext, buf.Bytes(), 0644)
+func (dc *DriveClient) ExportAuto(ctx context.Context, fileID string, w io.Writer, preferences ...ExportFormat) (ExportFormat, string, error) {
+	if fileID == "" {
+		return "", "", errors.New("file ID cannot be empty")
+	}
+
+	file, err := dc.service.Files.Get(fileID).
+		Context(ctx).
+		Fields("mimeType").
+		Do()
+	if err != nil {
+		return "", "", fmt.Errorf("unable to get file metadata: %w", err)
+	}
+	if !strings.HasPrefix(file.MimeType, "application/vnd.google-apps.") || file.MimeType == "application/vnd.google-apps.folder" {
+		return "", "", fmt.Errorf("file is not a Google Workspace document (MIME type: %s)", file.MimeType)
+	}
+
+	format, err := dc.negotiateExportFormat(ctx, WorkspaceType(file.MimeType), preferences)
+	if err != nil {
+		return "", "", err
+	}
+
+	_, err = dc.ExportWorkspaceDocument(ctx, fileID, w, format)
+	return format, format.Extension(), err
+}